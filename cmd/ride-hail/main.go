@@ -17,14 +17,14 @@ func Run() {
 	flag.Parse()
 	cfg, err := config.New(*modeConfigPAth, *modeFlag)
 	if err != nil {
-		slog.Error("error in parsing config", err)
+		slog.Error("error in parsing config", "error", err)
 		return
 	}
 
 	ctx := context.Background()
 	application, err := app.New(ctx, *cfg)
 	if err != nil {
-		slog.Error("error in creating app", err)
+		slog.Error("error in creating app", "error", err)
 		return
 	}
 	application.Start(ctx)
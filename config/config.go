@@ -27,6 +27,57 @@ type Config struct {
 		Secret      string
 		ExpireHours int
 	}
+	Dispatch Dispatch
+	Surge    Surge
+	Routing  Routing
+	Schedule Schedule
+	Rating   Rating
+}
+
+// Dispatch — сколько ждать водителя и как расширять поиск, прежде чем отменить поездку
+type Dispatch struct {
+	TimeoutSeconds int
+	MaxRedispatch  int
+	RadiusStepKm   float64
+	// MatchWorkers — сколько поездок DAL подбирает одновременно; это Prefetch и Workers консьюмера ride_requests
+	MatchWorkers int
+}
+
+// Surge — размер зон, окно подсчёта спроса и границы повышающего коэффициента
+type Surge struct {
+	ZonePrecision int
+	WindowMinutes int
+	MaxMultiplier float64
+	AckThreshold  float64
+}
+
+// Schedule — за сколько до подачи запланированная поездка уходит на подбор водителя
+// и в каких пределах можно выбирать время подачи
+type Schedule struct {
+	LeadMinutes       int
+	MinAdvanceMinutes int
+	MaxAdvanceDays    int
+}
+
+// Rating — средняя оценка считается по последним Window оценкам, вес каждой следующей более старой
+// умножается на Decay; при подборе каждая звезда ниже 5 весит как EtaMinutesPerStar минут подачи
+type Rating struct {
+	Window            int
+	Decay             float64
+	EtaMinutesPerStar float64
+}
+
+const (
+	RoutingHaversine = "haversine"
+	RoutingOSRM      = "osrm"
+)
+
+// Routing — чем считать расстояние и время в пути; ответы внешнего маршрутизатора кешируются на CacheTTLSeconds
+type Routing struct {
+	Provider        string
+	OSRMURL         string
+	TimeoutMs       int
+	CacheTTLSeconds int
 }
 
 func New(configPath, mode string) (*Config, error) {
@@ -39,6 +90,16 @@ func New(configPath, mode string) (*Config, error) {
 		return nil, errors.New("invalid Mode")
 	}
 
+	switch cfg.Routing.Provider {
+	case RoutingHaversine:
+	case RoutingOSRM:
+		if cfg.Routing.OSRMURL == "" {
+			return nil, errors.New("routing.osrm_url is required for osrm provider")
+		}
+	default:
+		return nil, errors.New("invalid routing provider")
+	}
+
 	cfg.printConfig()
 	return cfg, nil
 }
@@ -87,7 +148,7 @@ func parseConfig(filename string) (*Config, error) {
 		}
 
 		switch key {
-		case "postgres", "rabbitmq", "websocket", "services", "jwt":
+		case "postgres", "rabbitmq", "websocket", "services", "jwt", "dispatch", "surge", "routing", "schedule", "rating":
 			section = key
 
 		default:
@@ -115,6 +176,12 @@ func parseConfig(filename string) (*Config, error) {
 					cfg.RabbitMQ.User = value
 				case "password":
 					cfg.RabbitMQ.Password = value
+				case "prefetch":
+					cfg.RabbitMQ.Prefetch, _ = strconv.Atoi(value)
+				case "workers":
+					cfg.RabbitMQ.Workers, _ = strconv.Atoi(value)
+				case "management_port":
+					cfg.RabbitMQ.ManagementPort, _ = strconv.Atoi(value)
 				}
 			case "websocket":
 				if key == "port" {
@@ -136,6 +203,57 @@ func parseConfig(filename string) (*Config, error) {
 				case "expire_hours":
 					cfg.JWT.ExpireHours, _ = strconv.Atoi(value)
 				}
+			case "dispatch":
+				switch key {
+				case "timeout_seconds":
+					cfg.Dispatch.TimeoutSeconds, _ = strconv.Atoi(value)
+				case "max_redispatch":
+					cfg.Dispatch.MaxRedispatch, _ = strconv.Atoi(value)
+				case "radius_step_km":
+					cfg.Dispatch.RadiusStepKm, _ = strconv.ParseFloat(value, 64)
+				case "match_workers":
+					cfg.Dispatch.MatchWorkers, _ = strconv.Atoi(value)
+				}
+			case "surge":
+				switch key {
+				case "zone_precision":
+					cfg.Surge.ZonePrecision, _ = strconv.Atoi(value)
+				case "window_minutes":
+					cfg.Surge.WindowMinutes, _ = strconv.Atoi(value)
+				case "max_multiplier":
+					cfg.Surge.MaxMultiplier, _ = strconv.ParseFloat(value, 64)
+				case "ack_threshold":
+					cfg.Surge.AckThreshold, _ = strconv.ParseFloat(value, 64)
+				}
+			case "routing":
+				switch key {
+				case "provider":
+					cfg.Routing.Provider = value
+				case "osrm_url":
+					cfg.Routing.OSRMURL = value
+				case "timeout_ms":
+					cfg.Routing.TimeoutMs, _ = strconv.Atoi(value)
+				case "cache_ttl_seconds":
+					cfg.Routing.CacheTTLSeconds, _ = strconv.Atoi(value)
+				}
+			case "schedule":
+				switch key {
+				case "lead_minutes":
+					cfg.Schedule.LeadMinutes, _ = strconv.Atoi(value)
+				case "min_advance_minutes":
+					cfg.Schedule.MinAdvanceMinutes, _ = strconv.Atoi(value)
+				case "max_advance_days":
+					cfg.Schedule.MaxAdvanceDays, _ = strconv.Atoi(value)
+				}
+			case "rating":
+				switch key {
+				case "window":
+					cfg.Rating.Window, _ = strconv.Atoi(value)
+				case "decay":
+					cfg.Rating.Decay, _ = strconv.ParseFloat(value, 64)
+				case "eta_minutes_per_star":
+					cfg.Rating.EtaMinutesPerStar, _ = strconv.ParseFloat(value, 64)
+				}
 			}
 		}
 	}
@@ -146,6 +264,55 @@ func parseConfig(filename string) (*Config, error) {
 	if cfg.Database.MaxIdleTime == "" {
 		cfg.Database.MaxIdleTime = "15m"
 	}
+	if cfg.Dispatch.TimeoutSeconds <= 0 {
+		cfg.Dispatch.TimeoutSeconds = 30
+	}
+	if cfg.Dispatch.RadiusStepKm <= 0 {
+		cfg.Dispatch.RadiusStepKm = 5
+	}
+	if cfg.Dispatch.MatchWorkers <= 0 {
+		cfg.Dispatch.MatchWorkers = 32
+	}
+	if cfg.Surge.ZonePrecision <= 0 {
+		cfg.Surge.ZonePrecision = 5
+	}
+	if cfg.Surge.WindowMinutes <= 0 {
+		cfg.Surge.WindowMinutes = 10
+	}
+	if cfg.Surge.MaxMultiplier < 1 {
+		cfg.Surge.MaxMultiplier = 3
+	}
+	if cfg.Surge.AckThreshold < 1 {
+		cfg.Surge.AckThreshold = 1.5
+	}
+	if cfg.Routing.Provider == "" {
+		cfg.Routing.Provider = RoutingHaversine
+	}
+	if cfg.Routing.TimeoutMs <= 0 {
+		cfg.Routing.TimeoutMs = 2000
+	}
+	if cfg.Routing.CacheTTLSeconds <= 0 {
+		cfg.Routing.CacheTTLSeconds = 300
+	}
+	if cfg.Schedule.LeadMinutes <= 0 {
+		cfg.Schedule.LeadMinutes = 15
+	}
+	// поездку, до которой меньше lead_minutes, сразу пришлось бы отдавать на подбор
+	if cfg.Schedule.MinAdvanceMinutes < cfg.Schedule.LeadMinutes {
+		cfg.Schedule.MinAdvanceMinutes = max(30, cfg.Schedule.LeadMinutes)
+	}
+	if cfg.Schedule.MaxAdvanceDays <= 0 {
+		cfg.Schedule.MaxAdvanceDays = 30
+	}
+	if cfg.Rating.Window <= 0 {
+		cfg.Rating.Window = 100
+	}
+	if cfg.Rating.Decay <= 0 || cfg.Rating.Decay > 1 {
+		cfg.Rating.Decay = 0.98
+	}
+	if cfg.Rating.EtaMinutesPerStar < 0 {
+		cfg.Rating.EtaMinutesPerStar = 0
+	}
 
 	return &cfg, scanner.Err()
 }
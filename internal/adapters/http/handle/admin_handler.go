@@ -0,0 +1,202 @@
+package handle
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"ride-hail/internal/adapters/http/handle/dto"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/internal/core/ports"
+	"ride-hail/pkg/logger"
+)
+
+type AdminHandler struct {
+	svc ports.AdminService
+	log *logger.Logger
+}
+
+type AdminHandle interface {
+	OverviewMetrics(w http.ResponseWriter, r *http.Request)
+	ActiveRides(w http.ResponseWriter, r *http.Request)
+	DeadLetters(w http.ResponseWriter, r *http.Request)
+	ReplayDeadLetters(w http.ResponseWriter, r *http.Request)
+	ParkedOutbox(w http.ResponseWriter, r *http.Request)
+	ReplayParkedOutbox(w http.ResponseWriter, r *http.Request)
+	Tariffs(w http.ResponseWriter, r *http.Request)
+	CreateTariff(w http.ResponseWriter, r *http.Request)
+}
+
+func NewAdminHandler(svc ports.AdminService, log *logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		svc: svc,
+		log: log,
+	}
+}
+
+func (h *AdminHandler) OverviewMetrics(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("AdminHandler.OverviewMetrics")
+	ctx := r.Context()
+
+	resp, err := h.svc.GetOverview(ctx)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	log.Debug(ctx, action.AdminOverview, "overview metrics returned")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *AdminHandler) ActiveRides(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("AdminHandler.ActiveRides")
+	ctx := r.Context()
+
+	page, pageSize, msg := dto.ParsePagination(r.URL.Query())
+	if msg != "" {
+		log.Warn(ctx, action.AdminActiveRides, "invalid pagination", "error", msg)
+		writeJSON(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	resp, err := h.svc.GetActiveRides(ctx, page, pageSize)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	log.Debug(ctx, action.AdminActiveRides, "active rides returned", "count", len(resp.Rides))
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *AdminHandler) DeadLetters(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("AdminHandler.DeadLetters")
+	ctx := r.Context()
+
+	limit, msg := dto.ParseLimit(r.URL.Query())
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	letters, err := h.svc.ListDeadLetters(ctx, r.PathValue("queue"), limit)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	log.Debug(ctx, action.AdminDeadLetters, "dead letters returned", "count", len(letters))
+	writeJSON(w, http.StatusOK, letters)
+}
+
+func (h *AdminHandler) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("AdminHandler.ReplayDeadLetters")
+	ctx := r.Context()
+
+	limit, msg := dto.ParseLimit(r.URL.Query())
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	resp, err := h.svc.ReplayDeadLetters(ctx, r.PathValue("queue"), limit)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	log.Debug(ctx, action.AdminDeadLetters, "dead letters replayed", "count", resp.Replayed)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *AdminHandler) ParkedOutbox(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("AdminHandler.ParkedOutbox")
+	ctx := r.Context()
+
+	limit, msg := dto.ParseLimit(r.URL.Query())
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	messages, err := h.svc.ListParkedOutbox(ctx, limit)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	log.Debug(ctx, action.AdminOutbox, "parked outbox messages returned", "count", len(messages))
+	writeJSON(w, http.StatusOK, messages)
+}
+
+func (h *AdminHandler) ReplayParkedOutbox(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("AdminHandler.ReplayParkedOutbox")
+	ctx := r.Context()
+
+	limit, msg := dto.ParseLimit(r.URL.Query())
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	resp, err := h.svc.ReplayParkedOutbox(ctx, limit)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	log.Debug(ctx, action.AdminOutbox, "parked outbox messages replayed", "count", resp.Replayed)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *AdminHandler) Tariffs(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("AdminHandler.Tariffs")
+	ctx := r.Context()
+
+	tariffs, err := h.svc.ListTariffs(ctx)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	log.Debug(ctx, action.AdminTariffs, "tariffs returned", "count", len(tariffs))
+	writeJSON(w, http.StatusOK, tariffs)
+}
+
+func (h *AdminHandler) CreateTariff(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("AdminHandler.CreateTariff")
+	ctx := r.Context()
+
+	var req models.CreateTariffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error(ctx, action.AdminTariffs, "error decoding body", "error", err)
+		writeJSON(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if ok, msg := dto.ValidateTariffDTO(&req); !ok {
+		log.Warn(ctx, action.AdminTariffs, "invalid tariff", "error", msg)
+		writeJSON(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	tariff, err := h.svc.CreateTariff(ctx, req)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tariff)
+}
+
+func writeAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrQueueNotFound):
+		writeJSON(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, types.ErrInvalidTariff):
+		writeJSON(w, http.StatusBadRequest, err.Error())
+	default:
+		writeJSON(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+}
@@ -0,0 +1,81 @@
+package handle
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"ride-hail/internal/core/domain/models"
+	"strconv"
+	"time"
+)
+
+// wantsCSV — выгрузка в CSV включается параметром format=csv
+func wantsCSV(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "csv"
+}
+
+// writeCSV возвращает ошибку записи: заголовки уже отправлены, вызывающему остаётся только залогировать её
+func writeCSV(w http.ResponseWriter, filename string, header []string, rows [][]string) error {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	// WriteAll сам сбрасывает буфер и возвращает cw.Error()
+	return cw.WriteAll(rows)
+}
+
+func ridesCSV(rides []models.RideSummary) ([]string, [][]string) {
+	header := []string{
+		"ride_id", "ride_number", "status", "vehicle_type", "pickup_address", "destination_address",
+		"estimated_fare", "final_fare", "currency", "requested_at", "completed_at", "cancelled_at",
+	}
+
+	rows := make([][]string, 0, len(rides))
+	for _, r := range rides {
+		finalFare := ""
+		if r.FinalFare != nil {
+			finalFare = formatMoney(*r.FinalFare)
+		}
+
+		rows = append(rows, []string{
+			r.RideID, r.RideNumber, r.Status, r.VehicleType, r.PickupAddress, r.DestinationAddress,
+			formatMoney(r.EstimatedFare), finalFare, r.Currency,
+			r.RequestedAt.UTC().Format(time.RFC3339), formatTime(r.CompletedAt), formatTime(r.CancelledAt),
+		})
+	}
+
+	return header, rows
+}
+
+func sessionsCSV(sessions []models.SessionEarnings) ([]string, [][]string) {
+	header := []string{"session_id", "started_at", "ended_at", "duration_hours", "rides_completed", "earnings"}
+
+	rows := make([][]string, 0, len(sessions))
+	for _, s := range sessions {
+		rows = append(rows, []string{
+			s.SessionID,
+			s.StartedAt.UTC().Format(time.RFC3339),
+			formatTime(s.EndedAt),
+			strconv.FormatFloat(s.DurationHours, 'f', 2, 64),
+			strconv.Itoa(s.RidesCompleted),
+			formatMoney(s.Earnings),
+		})
+	}
+
+	return header, rows
+}
+
+func formatMoney(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,38 @@
+package handle
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type brokenWriter struct {
+	http.ResponseWriter
+}
+
+func (brokenWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestWriteCSV(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := writeCSV(rec, "rides.csv", []string{"ride_id", "status"}, [][]string{{"r1", "COMPLETED"}, {"r2", "with,comma"}})
+	if err != nil {
+		t.Fatalf("writeCSV() error = %v", err)
+	}
+
+	if want := "ride_id,status\nr1,COMPLETED\nr2,\"with,comma\"\n"; rec.Body.String() != want {
+		t.Errorf("body = %q, want %q", rec.Body.String(), want)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="rides.csv"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+}
+
+func TestWriteCSVReportsWriteError(t *testing.T) {
+	w := brokenWriter{httptest.NewRecorder()}
+	if err := writeCSV(w, "rides.csv", []string{"ride_id"}, [][]string{{"r1"}}); err == nil {
+		t.Fatal("writeCSV() error = nil, want write error")
+	}
+}
@@ -1,24 +1,50 @@
 package handle
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"ride-hail/internal/adapters/http/handle/dto"
+	"ride-hail/internal/adapters/http/websocket"
 	"ride-hail/internal/core/domain/action"
 	"ride-hail/internal/core/domain/models"
 	"ride-hail/internal/core/domain/types"
 	"ride-hail/internal/core/ports"
 	"ride-hail/pkg/logger"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type DalHandler struct {
 	svc ports.DalService
+	wsh websocket.DriverWSHandler
 	log *logger.Logger
 }
 
-func NewDalHandler(log *logger.Logger) *DalHandler {
-	return &DalHandler{log: log}
+type DalHandle interface {
+	Registration(w http.ResponseWriter, r *http.Request)
+	DriverGoesOnline(w http.ResponseWriter, r *http.Request)
+	DriverGoesOffline(w http.ResponseWriter, r *http.Request)
+	UpdateLocation(w http.ResponseWriter, r *http.Request)
+	EnRouteRide(w http.ResponseWriter, r *http.Request)
+	ArrivedRide(w http.ResponseWriter, r *http.Request)
+	StartRide(w http.ResponseWriter, r *http.Request)
+	CompleteRide(w http.ResponseWriter, r *http.Request)
+	ReachStop(w http.ResponseWriter, r *http.Request)
+	DriverRides(w http.ResponseWriter, r *http.Request)
+	DriverEarnings(w http.ResponseWriter, r *http.Request)
+	DriverWebSocket(w http.ResponseWriter, r *http.Request)
+}
+
+func NewDalHandler(svc ports.DalService, wsh websocket.DriverWSHandler, log *logger.Logger) *DalHandler {
+	return &DalHandler{
+		svc: svc,
+		wsh: wsh,
+		log: log,
+	}
 }
 
 func (h *DalHandler) Registration(w http.ResponseWriter, r *http.Request) {
@@ -27,7 +53,7 @@ func (h *DalHandler) Registration(w http.ResponseWriter, r *http.Request) {
 
 	log.Debug(ctx, action.Registration, "registration request started")
 
-	if logger.GetRole(ctx) != types.RoleCustomer {
+	if logger.GetRole(ctx) != types.RoleDriver {
 		log.Error(ctx, action.Registration, "invalid role")
 		writeJSON(w, http.StatusForbidden, "invalid role")
 		return
@@ -55,11 +81,11 @@ func (h *DalHandler) Registration(w http.ResponseWriter, r *http.Request) {
 	if err := h.svc.CreateNewDriver(ctx, models.Driver{
 		ID:            logger.GetUserID(ctx),
 		LicenseNumber: data.LicenseNumber,
-		VehicleType:   data.VehicleType,
+		VehicleType:   strings.ToUpper(data.VehicleType),
 		VehicleAttrs:  data.VehicleAttrs,
 		Status:        types.DriverStatusOffline,
 	}); err != nil {
-		writeJSON(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		writeDalError(w, err)
 		return
 	}
 
@@ -105,7 +131,7 @@ func (h *DalHandler) DriverGoesOnline(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		writeDalError(w, err)
 		return
 	}
 
@@ -122,6 +148,7 @@ func (h *DalHandler) DriverGoesOffline(w http.ResponseWriter, r *http.Request) {
 	if logger.GetRole(ctx) != types.RoleDriver {
 		log.Error(ctx, action.UpdateStatus, "invalid role")
 		writeJSON(w, http.StatusForbidden, "invalid role")
+		return
 	}
 
 	if logger.GetUserID(ctx) != extractDriverID(r) && logger.GetUserID(ctx) != "" {
@@ -131,7 +158,7 @@ func (h *DalHandler) DriverGoesOffline(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if driverInfo, err := h.svc.StatusClose(ctx, logger.GetUserID(ctx)); err != nil {
-		writeJSON(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		writeDalError(w, err)
 		return
 	} else {
 		writeJSON(w, http.StatusOK, driverInfo)
@@ -139,6 +166,219 @@ func (h *DalHandler) DriverGoesOffline(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *DalHandler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("DalHandler.UpdateLocation")
+	ctx := r.Context()
+	if logger.GetRole(ctx) != types.RoleDriver {
+		log.Error(ctx, action.Location, "invalid role")
+		writeJSON(w, http.StatusForbidden, "invalid role")
+		return
+	}
+
+	if logger.GetUserID(ctx) != extractDriverID(r) && logger.GetUserID(ctx) != "" {
+		log.Error(ctx, action.Location, "invalid driver_id")
+		writeJSON(w, http.StatusBadRequest, "invalid driver_id")
+		return
+	}
+
+	var location models.LocationUpdate
+	if err := json.NewDecoder(r.Body).Decode(&location); err != nil {
+		log.Error(ctx, action.Location, "decode error", "error", err)
+		writeJSON(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if errMsg := dto.ValidateLocationUpdate(location); errMsg != "" {
+		log.Warn(ctx, action.Location, "validate error", "error", errMsg)
+		writeJSON(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	coordinateID, err := h.svc.UpdateLocation(ctx, logger.GetUserID(ctx), location)
+	if err != nil {
+		writeDalError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"coordinate_id": coordinateID,
+		"updated_at":    time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+func (h *DalHandler) EnRouteRide(w http.ResponseWriter, r *http.Request) {
+	h.progressRide(w, r, "DalHandler.EnRouteRide", h.svc.EnRouteRide)
+}
+
+func (h *DalHandler) ArrivedRide(w http.ResponseWriter, r *http.Request) {
+	h.progressRide(w, r, "DalHandler.ArrivedRide", h.svc.ArriveRide)
+}
+
+func (h *DalHandler) StartRide(w http.ResponseWriter, r *http.Request) {
+	h.progressRide(w, r, "DalHandler.StartRide", h.svc.StartRide)
+}
+
+func (h *DalHandler) CompleteRide(w http.ResponseWriter, r *http.Request) {
+	h.progressRide(w, r, "DalHandler.CompleteRide", h.svc.CompleteRide)
+}
+
+func (h *DalHandler) ReachStop(w http.ResponseWriter, r *http.Request) {
+	seq, err := strconv.Atoi(r.PathValue("seq"))
+	if err != nil || seq <= 0 {
+		h.log.Func("DalHandler.ReachStop").Warn(r.Context(), action.RideProgress, "invalid stop seq", "seq", r.PathValue("seq"))
+		writeJSON(w, http.StatusBadRequest, "invalid stop seq")
+		return
+	}
+
+	h.progressRide(w, r, "DalHandler.ReachStop", func(ctx context.Context, driverID, rideID string) (models.RideProgressResponse, error) {
+		return h.svc.ReachStop(ctx, driverID, rideID, seq)
+	})
+}
+
+func (h *DalHandler) progressRide(w http.ResponseWriter, r *http.Request, name string, step func(ctx context.Context, driverID, rideID string) (models.RideProgressResponse, error)) {
+	log := h.log.Func(name)
+	ctx := r.Context()
+	if logger.GetRole(ctx) != types.RoleDriver {
+		log.Error(ctx, action.RideProgress, "invalid role")
+		writeJSON(w, http.StatusForbidden, "invalid role")
+		return
+	}
+
+	if logger.GetUserID(ctx) != r.PathValue("driver_id") {
+		log.Error(ctx, action.RideProgress, "invalid driver_id")
+		writeJSON(w, http.StatusBadRequest, "invalid driver_id")
+		return
+	}
+
+	rideID := r.PathValue("ride_id")
+	if rideID == "" {
+		log.Error(ctx, action.RideProgress, "invalid ride_id")
+		writeJSON(w, http.StatusBadRequest, "invalid ride_id")
+		return
+	}
+
+	resp, err := step(ctx, logger.GetUserID(ctx), rideID)
+	if err != nil {
+		writeDalError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *DalHandler) DriverRides(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("DalHandler.DriverRides")
+	ctx := r.Context()
+	if logger.GetRole(ctx) != types.RoleDriver {
+		log.Error(ctx, action.DriverHistory, "invalid role")
+		writeJSON(w, http.StatusForbidden, "invalid role")
+		return
+	}
+
+	if logger.GetUserID(ctx) != r.PathValue("driver_id") {
+		log.Error(ctx, action.DriverHistory, "invalid driver_id")
+		writeJSON(w, http.StatusBadRequest, "invalid driver_id")
+		return
+	}
+
+	filter, msg := dto.ParseRideFilter(r.URL.Query())
+	if msg != "" {
+		log.Warn(ctx, action.DriverHistory, "invalid query", "reason", msg)
+		writeJSON(w, http.StatusBadRequest, msg)
+		return
+	}
+	filter.DriverID = logger.GetUserID(ctx)
+
+	page, err := h.svc.ListRides(ctx, filter)
+	if err != nil {
+		writeDalError(w, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		if page.NextCursor != "" {
+			w.Header().Set("X-Next-Cursor", page.NextCursor)
+		}
+		header, rows := ridesCSV(page.Rides)
+		if err = writeCSV(w, "rides.csv", header, rows); err != nil {
+			log.Error(ctx, action.DriverHistory, "error writing rides csv", "error", err)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h *DalHandler) DriverEarnings(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("DalHandler.DriverEarnings")
+	ctx := r.Context()
+	if logger.GetRole(ctx) != types.RoleDriver {
+		log.Error(ctx, action.DriverHistory, "invalid role")
+		writeJSON(w, http.StatusForbidden, "invalid role")
+		return
+	}
+
+	if logger.GetUserID(ctx) != r.PathValue("driver_id") {
+		log.Error(ctx, action.DriverHistory, "invalid driver_id")
+		writeJSON(w, http.StatusBadRequest, "invalid driver_id")
+		return
+	}
+
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = types.EarningsPeriodDay
+	}
+
+	earnings, err := h.svc.GetEarnings(ctx, logger.GetUserID(ctx), period)
+	if err != nil {
+		writeDalError(w, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		header, rows := sessionsCSV(earnings.Sessions)
+		if err = writeCSV(w, fmt.Sprintf("earnings-%s-%s.csv", period, earnings.From.Format(time.DateOnly)), header, rows); err != nil {
+			log.Error(ctx, action.DriverHistory, "error writing earnings csv", "error", err)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, earnings)
+}
+
+func (h *DalHandler) DriverWebSocket(w http.ResponseWriter, r *http.Request) {
+	h.wsh.DriverWebSocketHandler(w, r)
+}
+
+func writeDalError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrInvalidPeriod),
+		errors.Is(err, types.ErrInvalidCursor):
+		writeJSON(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, types.ErrDriverNotFound),
+		errors.Is(err, types.ErrRideNotFound),
+		errors.Is(err, types.ErrStopNotFound):
+		writeJSON(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, types.ErrRideAccessDenied),
+		errors.Is(err, types.ErrTransitionForbidden):
+		writeJSON(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, types.ErrDriverExists),
+		errors.Is(err, types.ErrDriverOnline),
+		errors.Is(err, types.ErrDriverStatusNotAllow),
+		errors.Is(err, types.ErrInvalidTransition),
+		errors.Is(err, types.ErrStopOutOfOrder),
+		errors.Is(err, types.ErrStopsNotReached),
+		errors.Is(err, types.ErrRideStatusConflict):
+		writeJSON(w, http.StatusConflict, err.Error())
+	case errors.Is(err, types.ErrDriverDocumentsExpired):
+		writeJSON(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, types.ErrLocationRateLimited):
+		writeJSON(w, http.StatusTooManyRequests, err.Error())
+	default:
+		writeJSON(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+}
+
 func extractDriverID(r *http.Request) string {
 	path := r.URL.Path
 	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"net/url"
+	"ride-hail/internal/core/domain/models"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// ParsePagination читает page и page_size из query, пустые значения заменяются значениями по умолчанию
+func ParsePagination(q url.Values) (int, int, string) {
+	page, pageSize := 1, defaultPageSize
+
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, 0, "page must be a positive integer"
+		}
+		page = n
+	}
+
+	if v := q.Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			return 0, 0, "page_size must be between 1 and 100"
+		}
+		pageSize = n
+	}
+
+	return page, pageSize, ""
+}
+
+// ParseLimit читает limit из query, по умолчанию defaultPageSize
+func ParseLimit(q url.Values) (int, string) {
+	v := q.Get("limit")
+	if v == "" {
+		return defaultPageSize, ""
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxPageSize {
+		return 0, "limit must be between 1 and 100"
+	}
+	return n, ""
+}
+
+// ValidateTariffDTO проверяет формат новой версии тарифа, тип машины и валюта приводятся к верхнему регистру;
+// суммы и дату вступления в силу проверяет AdminService
+func ValidateTariffDTO(dto *models.CreateTariffRequest) (bool, string) {
+	var reasons []string
+
+	dto.VehicleType = strings.ToUpper(strings.TrimSpace(dto.VehicleType))
+	if !isAllowedRideType(dto.VehicleType) {
+		reasons = append(reasons, "invalid_vehicle_type")
+	}
+
+	dto.Currency = strings.ToUpper(strings.TrimSpace(dto.Currency))
+	if dto.Currency == "" {
+		dto.Currency = "KZT"
+	}
+	if len(dto.Currency) != 3 {
+		reasons = append(reasons, "invalid_currency")
+	}
+
+	return len(reasons) == 0, strings.Join(reasons, ", ")
+}
@@ -2,6 +2,7 @@ package dto
 
 import (
 	"fmt"
+	"ride-hail/internal/core/domain/models"
 	"strings"
 )
 
@@ -18,42 +19,42 @@ type DriverRegistration struct {
 		Seats             int    `json:"seats"`
 		InsuranceExpiry   string `json:"insurance_expiry"`
 		TaxiLicenseExpiry string `json:"taxi_license_expiry"`
-	}
+	} `json:"vehicle_attrs"`
 }
 
 func (d DriverRegistration) Validate() string {
 	result := make([]string, 0)
-	if d.LicenseNumber != "" {
+	if strings.TrimSpace(d.LicenseNumber) == "" {
 		result = append(result, "invalid license number\n")
 	}
-	if d.VehicleType != "" {
+	if !isAllowedRideType(d.VehicleType) {
 		result = append(result, "invalid vehicle type\n")
 	}
-	if d.VehicleAttrs.LicensePlate != "" {
+	if strings.TrimSpace(d.VehicleAttrs.LicensePlate) == "" {
 		result = append(result, "invalid license plate\n")
 	}
-	if d.VehicleAttrs.InspectionDate != "" {
+	if strings.TrimSpace(d.VehicleAttrs.InspectionDate) == "" {
 		result = append(result, "invalid inspection date\n")
 	}
-	if d.VehicleAttrs.Make != "" {
+	if strings.TrimSpace(d.VehicleAttrs.Make) == "" {
 		result = append(result, "invalid make\n")
 	}
-	if d.VehicleAttrs.Model != "" {
+	if strings.TrimSpace(d.VehicleAttrs.Model) == "" {
 		result = append(result, "invalid model\n")
 	}
 	if d.VehicleAttrs.Year < 2000 {
 		result = append(result, "invalid year\n")
 	}
-	if d.VehicleAttrs.Color != "" {
+	if strings.TrimSpace(d.VehicleAttrs.Color) == "" {
 		result = append(result, "invalid color\n")
 	}
 	if d.VehicleAttrs.Seats <= 0 || d.VehicleAttrs.Seats > 7 {
 		result = append(result, "invalid seats\n")
 	}
-	if d.VehicleAttrs.InsuranceExpiry != "" {
+	if strings.TrimSpace(d.VehicleAttrs.InsuranceExpiry) == "" {
 		result = append(result, "invalid insurance expiry\n")
 	}
-	if d.VehicleAttrs.TaxiLicenseExpiry != "" {
+	if strings.TrimSpace(d.VehicleAttrs.TaxiLicenseExpiry) == "" {
 		result = append(result, "invalid taxi license expiry\n")
 	}
 	return fmt.Sprintf("%s", strings.Join(result, ""))
@@ -68,11 +69,27 @@ func (l Location) Validate() string {
 	var result []string
 
 	if l.Latitude < -90 || l.Latitude > 90 {
-		result = append(result, "latitude must be between -90 and 90")
+		result = append(result, "latitude must be between -90 and 90\n")
 	}
 	if l.Longitude < -180 || l.Longitude > 180 {
-		result = append(result, "longitude must be between -180 and 180")
+		result = append(result, "longitude must be between -180 and 180\n")
 	}
 
 	return fmt.Sprintf("%s", strings.Join(result, ""))
 }
+
+func ValidateLocationUpdate(l models.LocationUpdate) string {
+	result := []string{Location{Latitude: l.Latitude, Longitude: l.Longitude}.Validate()}
+
+	if l.AccuracyMeters < 0 || l.AccuracyMeters >= 10000 {
+		result = append(result, "accuracy_meters must be between 0 and 9999.99\n")
+	}
+	if l.SpeedKmh < 0 || l.SpeedKmh >= 1000 {
+		result = append(result, "speed_kmh must be between 0 and 999.99\n")
+	}
+	if l.HeadingDegrees < 0 || l.HeadingDegrees > 360 {
+		result = append(result, "heading_degrees must be between 0 and 360\n")
+	}
+
+	return strings.Join(result, "")
+}
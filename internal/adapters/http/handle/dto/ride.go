@@ -2,17 +2,30 @@ package dto
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
 	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"slices"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type RideRules struct {
-	AllowRideTypes []string
+	AllowRideTypes   []string
+	MaxStops         int
+	MaxRatingTags    int
+	MaxRatingTagLen  int
+	MaxRatingComment int
 }
 
 var DefaultRideRules = RideRules{
-	AllowRideTypes: []string{"ECONOMY", "PREMIUM", "XL"},
+	AllowRideTypes:   []string{"ECONOMY", "PREMIUM", "XL"},
+	MaxStops:         5,
+	MaxRatingTags:    5,
+	MaxRatingTagLen:  32,
+	MaxRatingComment: 500,
 }
 
 func isValidUUID(u string) bool {
@@ -20,6 +33,15 @@ func isValidUUID(u string) bool {
 	return re.MatchString(u)
 }
 
+func isAllowedRideType(rideType string) bool {
+	for _, allowed := range DefaultRideRules.AllowRideTypes {
+		if strings.EqualFold(rideType, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
 func ValidateRideDTO(dto models.CreateRideRequest) (bool, string) {
 	var reasons []string
 
@@ -28,37 +50,205 @@ func ValidateRideDTO(dto models.CreateRideRequest) (bool, string) {
 		reasons = append(reasons, "invalid_passenger_id")
 	}
 
-	// Проверка координат
-	if dto.PickupLatitude < -90 || dto.PickupLatitude > 90 {
+	reasons = append(reasons, validateCoordinates(dto.PickupLatitude, dto.PickupLongitude, dto.DestinationLatitude, dto.DestinationLongitude)...)
+
+	if strings.TrimSpace(dto.PickupAddress) == "" {
+		reasons = append(reasons, "empty_pickup_address")
+	}
+	if strings.TrimSpace(dto.DestinationAddress) == "" {
+		reasons = append(reasons, "empty_destination_address")
+	}
+
+	reasons = append(reasons, validateStops(dto.Stops, true)...)
+
+	if !isAllowedRideType(dto.RideType) {
+		reasons = append(reasons, fmt.Sprintf("invalid_ride_type: %s", dto.RideType))
+	}
+
+	// цена из оценки включает текущий surge, а запланированная поездка считается по тарифу на время подачи
+	if dto.ScheduledAt != nil && dto.EstimateToken != "" {
+		reasons = append(reasons, "estimate_token_not_allowed_for_scheduled_ride")
+	}
+
+	return len(reasons) == 0, strings.Join(reasons, ", ")
+}
+
+func ValidateUpdateRideDTO(dto models.UpdateRideRequest) (bool, string) {
+	var reasons []string
+
+	if !isValidUUID(dto.RideID) {
+		reasons = append(reasons, "invalid_ride_id")
+	}
+
+	if dto.ScheduledAt == nil && dto.PickupLocation == nil && dto.DestinationLocation == nil {
+		reasons = append(reasons, "nothing_to_update")
+	}
+
+	if dto.PickupLocation != nil {
+		if dto.PickupLocation.Lat < -90 || dto.PickupLocation.Lat > 90 {
+			reasons = append(reasons, "invalid_pickup_latitude")
+		}
+		if dto.PickupLocation.Lng < -180 || dto.PickupLocation.Lng > 180 {
+			reasons = append(reasons, "invalid_pickup_longitude")
+		}
+		if strings.TrimSpace(dto.PickupLocation.Address) == "" {
+			reasons = append(reasons, "empty_pickup_address")
+		}
+	}
+
+	if dto.DestinationLocation != nil {
+		if dto.DestinationLocation.Lat < -90 || dto.DestinationLocation.Lat > 90 {
+			reasons = append(reasons, "invalid_destination_latitude")
+		}
+		if dto.DestinationLocation.Lng < -180 || dto.DestinationLocation.Lng > 180 {
+			reasons = append(reasons, "invalid_destination_longitude")
+		}
+		if strings.TrimSpace(dto.DestinationLocation.Address) == "" {
+			reasons = append(reasons, "empty_destination_address")
+		}
+	}
+
+	return len(reasons) == 0, strings.Join(reasons, ", ")
+}
+
+func ValidateEstimateDTO(dto models.EstimateRideRequest) (bool, string) {
+	reasons := validateCoordinates(dto.PickupLatitude, dto.PickupLongitude, dto.DestinationLatitude, dto.DestinationLongitude)
+	reasons = append(reasons, validateStops(dto.Stops, false)...)
+	return len(reasons) == 0, strings.Join(reasons, ", ")
+}
+
+// Проверка промежуточных остановок; для оценки адрес не нужен
+func validateStops(stops []models.Location, needAddress bool) []string {
+	var reasons []string
+
+	if len(stops) > DefaultRideRules.MaxStops {
+		reasons = append(reasons, fmt.Sprintf("too_many_stops: max %d", DefaultRideRules.MaxStops))
+	}
+
+	for i, s := range stops {
+		if s.Lat < -90 || s.Lat > 90 || s.Lng < -180 || s.Lng > 180 {
+			reasons = append(reasons, fmt.Sprintf("invalid_stop_%d_coordinates", i+1))
+		}
+		if needAddress && strings.TrimSpace(s.Address) == "" {
+			reasons = append(reasons, fmt.Sprintf("empty_stop_%d_address", i+1))
+		}
+	}
+
+	return reasons
+}
+
+// Проверка координат
+func validateCoordinates(pickupLat, pickupLng, destLat, destLng float64) []string {
+	var reasons []string
+
+	if pickupLat < -90 || pickupLat > 90 {
 		reasons = append(reasons, "invalid_pickup_latitude")
 	}
-	if dto.PickupLongitude < -180 || dto.PickupLongitude > 180 {
+	if pickupLng < -180 || pickupLng > 180 {
 		reasons = append(reasons, "invalid_pickup_longitude")
 	}
-	if dto.DestinationLatitude < -90 || dto.DestinationLatitude > 90 {
+	if destLat < -90 || destLat > 90 {
 		reasons = append(reasons, "invalid_destination_latitude")
 	}
-	if dto.DestinationLongitude < -180 || dto.DestinationLongitude > 180 {
+	if destLng < -180 || destLng > 180 {
 		reasons = append(reasons, "invalid_destination_longitude")
 	}
 
-	if strings.TrimSpace(dto.PickupAddress) == "" {
-		reasons = append(reasons, "empty_pickup_address")
+	return reasons
+}
+
+// ParseRideFilter читает status (через запятую), from, to, cursor и limit из query истории поездок.
+// Даты принимаются в RFC3339 или YYYY-MM-DD; дата без времени в to включает весь день
+func ParseRideFilter(q url.Values) (models.RideFilter, string) {
+	var (
+		filter  models.RideFilter
+		reasons []string
+	)
+
+	if v := q.Get("status"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			s = strings.ToUpper(strings.TrimSpace(s))
+			if !slices.Contains(types.RideStatuses, s) {
+				reasons = append(reasons, fmt.Sprintf("invalid_status: %s", s))
+				continue
+			}
+			filter.Statuses = append(filter.Statuses, s)
+		}
 	}
-	if strings.TrimSpace(dto.DestinationAddress) == "" {
-		reasons = append(reasons, "empty_destination_address")
+
+	if v := q.Get("from"); v != "" {
+		from, _, ok := parseDateParam(v)
+		if !ok {
+			reasons = append(reasons, "invalid_from")
+		} else {
+			filter.From = &from
+		}
 	}
 
-	validType := false
-	for _, allowed := range DefaultRideRules.AllowRideTypes {
-		if strings.EqualFold(dto.RideType, allowed) {
-			validType = true
-			break
+	if v := q.Get("to"); v != "" {
+		to, dateOnly, ok := parseDateParam(v)
+		if !ok {
+			reasons = append(reasons, "invalid_to")
+		} else {
+			if dateOnly {
+				to = to.AddDate(0, 0, 1)
+			}
+			filter.To = &to
 		}
 	}
-	if !validType {
-		reasons = append(reasons, fmt.Sprintf("invalid_ride_type: %s", dto.RideType))
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		reasons = append(reasons, "from_must_be_before_to")
+	}
+
+	limit, msg := ParseLimit(q)
+	if msg != "" {
+		reasons = append(reasons, msg)
+	}
+	filter.Limit = limit
+	filter.Cursor = q.Get("cursor")
+
+	return filter, strings.Join(reasons, ", ")
+}
+
+func parseDateParam(v string) (time.Time, bool, bool) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, false, true
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, true, true
+	}
+	return time.Time{}, false, false
+}
+
+// ValidateRateRideDTO проверяет оценку; теги обрезаются, приводятся к нижнему регистру и избавляются от повторов
+func ValidateRateRideDTO(dto *models.RateRideRequest) (bool, string) {
+	var reasons []string
+
+	if dto.Score < 1 || dto.Score > 5 {
+		reasons = append(reasons, "score_must_be_between_1_and_5")
+	}
+
+	dto.Comment = strings.TrimSpace(dto.Comment)
+	if utf8.RuneCountInString(dto.Comment) > DefaultRideRules.MaxRatingComment {
+		reasons = append(reasons, fmt.Sprintf("comment_longer_than_%d", DefaultRideRules.MaxRatingComment))
+	}
+
+	tags := make([]string, 0, len(dto.Tags))
+	for _, t := range dto.Tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || utf8.RuneCountInString(t) > DefaultRideRules.MaxRatingTagLen {
+			reasons = append(reasons, "invalid_tag")
+			continue
+		}
+		if !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	if len(tags) > DefaultRideRules.MaxRatingTags {
+		reasons = append(reasons, fmt.Sprintf("too_many_tags: max %d", DefaultRideRules.MaxRatingTags))
 	}
+	dto.Tags = tags
 
 	return len(reasons) == 0, strings.Join(reasons, ", ")
 }
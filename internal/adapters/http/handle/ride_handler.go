@@ -2,6 +2,7 @@ package handle
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"ride-hail/internal/adapters/http/handle/dto"
 	"ride-hail/internal/adapters/http/websocket"
@@ -15,19 +16,28 @@ import (
 
 type RideHandle struct {
 	svc ports.RideService
+	wsh websocket.PassengerWSHandler
 	log *logger.Logger
 }
 
-func NewRideHandle(svc ports.RideService, wsm websocket.PassengerWSHandler, log *logger.Logger) *RideHandle {
+func NewRideHandle(svc ports.RideService, wsh websocket.PassengerWSHandler, log *logger.Logger) *RideHandle {
 	return &RideHandle{
 		svc: svc,
+		wsh: wsh,
 		log: log,
 	}
 }
 
 type RideHandler interface {
 	CreateNewRide(w http.ResponseWriter, r *http.Request)
+	EstimateRide(w http.ResponseWriter, r *http.Request)
 	CancelRide(w http.ResponseWriter, r *http.Request)
+	UpdateRide(w http.ResponseWriter, r *http.Request)
+	RideEvents(w http.ResponseWriter, r *http.Request)
+	ListRides(w http.ResponseWriter, r *http.Request)
+	GetRide(w http.ResponseWriter, r *http.Request)
+	RateRide(w http.ResponseWriter, r *http.Request)
+	PassengerWebSocket(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *RideHandle) CreateNewRide(w http.ResponseWriter, r *http.Request) {
@@ -56,7 +66,7 @@ func (h *RideHandle) CreateNewRide(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if resp, err := h.svc.CreateNewRide(ctx, rideDto); err != nil {
-		writeJSON(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		writeRideError(w, err)
 		return
 	} else {
 		log.Debug(ctx, action.CreateRide, "the request to create a trip was successfully completed")
@@ -65,6 +75,38 @@ func (h *RideHandle) CreateNewRide(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *RideHandle) EstimateRide(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("RideHandle.EstimateRide")
+	ctx := r.Context()
+
+	if logger.GetRole(ctx) != types.RoleCustomer {
+		log.Error(ctx, action.EstimateRide, "invalid role", "role", logger.GetRole(ctx))
+		writeJSON(w, http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	var req models.EstimateRideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error(ctx, action.EstimateRide, "error decoding body", "error", err)
+		writeJSON(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if ok, msg := dto.ValidateEstimateDTO(req); !ok {
+		log.Warn(ctx, action.EstimateRide, "invalid request")
+		writeJSON(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	resp, err := h.svc.EstimateRide(ctx, req)
+	if err != nil {
+		writeRideError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
 func (h *RideHandle) CancelRide(w http.ResponseWriter, r *http.Request) {
 	log := h.log.Func("RideHandle.CancelRide")
 	ctx := r.Context()
@@ -87,7 +129,7 @@ func (h *RideHandle) CancelRide(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if resp, err := h.svc.CloseRide(ctx, closeReq); err != nil {
-		writeJSON(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		writeRideError(w, err)
 		return
 	} else {
 		log.Debug(ctx, action.CloseRide, "the request to cancel the ride has been completed")
@@ -96,6 +138,160 @@ func (h *RideHandle) CancelRide(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *RideHandle) UpdateRide(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("RideHandle.UpdateRide")
+	ctx := r.Context()
+
+	if logger.GetRole(ctx) != types.RoleCustomer {
+		log.Error(ctx, action.ScheduleRide, "invalid role", "role", logger.GetRole(ctx))
+		writeJSON(w, http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	var req models.UpdateRideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error(ctx, action.ScheduleRide, "error decoding body", "error", err)
+		writeJSON(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.RideID = r.PathValue("ride_id")
+
+	if ok, msg := dto.ValidateUpdateRideDTO(req); !ok {
+		log.Warn(ctx, action.ScheduleRide, "invalid request")
+		writeJSON(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	resp, err := h.svc.UpdateScheduledRide(ctx, req)
+	if err != nil {
+		writeRideError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *RideHandle) RideEvents(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("RideHandle.RideEvents")
+	ctx := r.Context()
+
+	events, err := h.svc.GetRideEvents(ctx, r.PathValue("ride_id"))
+	if err != nil {
+		writeRideError(w, err)
+		return
+	}
+
+	log.Debug(ctx, action.RideEvents, "ride events returned", "count", len(events))
+	writeJSON(w, http.StatusOK, events)
+}
+
+func (h *RideHandle) ListRides(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("RideHandle.ListRides")
+	ctx := r.Context()
+
+	if logger.GetRole(ctx) != types.RoleCustomer {
+		log.Error(ctx, action.RideHistory, "invalid role", "role", logger.GetRole(ctx))
+		writeJSON(w, http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	filter, msg := dto.ParseRideFilter(r.URL.Query())
+	if msg != "" {
+		log.Warn(ctx, action.RideHistory, "invalid query", "reason", msg)
+		writeJSON(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	page, err := h.svc.ListRides(ctx, filter)
+	if err != nil {
+		writeRideError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h *RideHandle) GetRide(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("RideHandle.GetRide")
+	ctx := r.Context()
+
+	if logger.GetRole(ctx) != types.RoleCustomer {
+		log.Error(ctx, action.RideHistory, "invalid role", "role", logger.GetRole(ctx))
+		writeJSON(w, http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	ride, err := h.svc.GetRideDetail(ctx, r.PathValue("ride_id"))
+	if err != nil {
+		writeRideError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ride)
+}
+
+func (h *RideHandle) RateRide(w http.ResponseWriter, r *http.Request) {
+	log := h.log.Func("RideHandle.RateRide")
+	ctx := r.Context()
+
+	if role := logger.GetRole(ctx); role != types.RoleCustomer && role != types.RoleDriver {
+		log.Error(ctx, action.RateRide, "invalid role", "role", role)
+		writeJSON(w, http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	var req models.RateRideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error(ctx, action.RateRide, "error decoding body", "error", err)
+		writeJSON(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.RideID = r.PathValue("ride_id")
+
+	if ok, msg := dto.ValidateRateRideDTO(&req); !ok {
+		log.Warn(ctx, action.RateRide, "invalid request")
+		writeJSON(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	resp, err := h.svc.RateRide(ctx, req)
+	if err != nil {
+		writeRideError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (h *RideHandle) PassengerWebSocket(w http.ResponseWriter, r *http.Request) {
+	h.wsh.PassengerWebSocketHandler(w, r)
+}
+
+func writeRideError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrEstimateInvalid),
+		errors.Is(err, types.ErrInvalidSchedule),
+		errors.Is(err, types.ErrInvalidCursor):
+		writeJSON(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, types.ErrEstimateExpired):
+		writeJSON(w, http.StatusGone, err.Error())
+	case errors.Is(err, types.ErrRideNotFound):
+		writeJSON(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, types.ErrRideAccessDenied),
+		errors.Is(err, types.ErrTransitionForbidden):
+		writeJSON(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, types.ErrInvalidTransition),
+		errors.Is(err, types.ErrSurgeNotAcknowledged),
+		errors.Is(err, types.ErrRideNotScheduled),
+		errors.Is(err, types.ErrRideNotCompleted),
+		errors.Is(err, types.ErrAlreadyRated),
+		errors.Is(err, types.ErrRideStatusConflict):
+		writeJSON(w, http.StatusConflict, err.Error())
+	default:
+		writeJSON(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+}
+
 func getRideID(r *http.Request) string {
 	path := r.URL.Path
 	parts := strings.Split(path, "/")
@@ -106,6 +106,19 @@ func (a *API) jwtMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// roleMiddleware пропускает только пользователей с указанной ролью, ставится после jwtMiddleware
+func (a *API) roleMiddleware(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if got := logger.GetRole(r.Context()); got != role {
+			a.log.Func("api.roleMiddleware").Warn(r.Context(), action.Authorization, "role is not allowed", "role", got)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
 func newRequestID() string {
 	b := make([]byte, 16)
 	if _, err := rand.Read(b); err != nil {
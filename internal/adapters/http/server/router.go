@@ -14,7 +14,13 @@ func (a *API) setupRoutes(mux *http.ServeMux) error {
 
 	switch a.cfg.Mode {
 	case types.ModeAdmin:
+		if err := a.setupAdminRoutes(mux); err != nil {
+			return err
+		}
 	case types.ModeDAL:
+		if err := a.setupDalRoutes(mux); err != nil {
+			return err
+		}
 	case types.ModeRide:
 		if err := a.setupRideRoutes(mux); err != nil {
 			return err
@@ -36,8 +42,51 @@ func (a *API) setupRideRoutes(mux *http.ServeMux) error {
 	if a.h.ride == nil {
 		return errors.New("ride service is required")
 	}
-	mux.HandleFunc("/rides", a.jwtMiddleware(a.h.ride.CreateNewRide))
+	mux.HandleFunc("POST /rides", a.jwtMiddleware(a.h.ride.CreateNewRide))
+	mux.HandleFunc("GET /rides", a.jwtMiddleware(a.h.ride.ListRides))
+	mux.HandleFunc("POST /rides/estimate", a.jwtMiddleware(a.h.ride.EstimateRide))
+	mux.HandleFunc("GET /rides/{ride_id}", a.jwtMiddleware(a.h.ride.GetRide))
+	mux.HandleFunc("PATCH /rides/{ride_id}", a.jwtMiddleware(a.h.ride.UpdateRide))
 	mux.HandleFunc("/rides/{ride_id}/cancel", a.jwtMiddleware(a.h.ride.CancelRide))
+	mux.HandleFunc("GET /rides/{ride_id}/events", a.jwtMiddleware(a.h.ride.RideEvents))
+	mux.HandleFunc("POST /rides/{ride_id}/rating", a.jwtMiddleware(a.h.ride.RateRide))
+	mux.HandleFunc("GET /ws/passengers/{passenger_id}", a.jwtMiddleware(a.h.ride.PassengerWebSocket))
+
+	return nil
+}
+
+func (a *API) setupDalRoutes(mux *http.ServeMux) error {
+	if a.h.dal == nil {
+		return errors.New("driver service is required")
+	}
+	mux.HandleFunc("POST /drivers", a.jwtMiddleware(a.h.dal.Registration))
+	mux.HandleFunc("POST /drivers/{driver_id}/online", a.jwtMiddleware(a.h.dal.DriverGoesOnline))
+	mux.HandleFunc("POST /drivers/{driver_id}/offline", a.jwtMiddleware(a.h.dal.DriverGoesOffline))
+	mux.HandleFunc("POST /drivers/{driver_id}/location", a.jwtMiddleware(a.h.dal.UpdateLocation))
+	mux.HandleFunc("POST /drivers/{driver_id}/rides/{ride_id}/en-route", a.jwtMiddleware(a.h.dal.EnRouteRide))
+	mux.HandleFunc("POST /drivers/{driver_id}/rides/{ride_id}/arrived", a.jwtMiddleware(a.h.dal.ArrivedRide))
+	mux.HandleFunc("POST /drivers/{driver_id}/rides/{ride_id}/start", a.jwtMiddleware(a.h.dal.StartRide))
+	mux.HandleFunc("POST /drivers/{driver_id}/rides/{ride_id}/complete", a.jwtMiddleware(a.h.dal.CompleteRide))
+	mux.HandleFunc("POST /drivers/{driver_id}/rides/{ride_id}/stops/{seq}/reached", a.jwtMiddleware(a.h.dal.ReachStop))
+	mux.HandleFunc("GET /drivers/{driver_id}/rides", a.jwtMiddleware(a.h.dal.DriverRides))
+	mux.HandleFunc("GET /drivers/{driver_id}/earnings", a.jwtMiddleware(a.h.dal.DriverEarnings))
+	mux.HandleFunc("GET /ws/drivers/{driver_id}", a.jwtMiddleware(a.h.dal.DriverWebSocket))
+
+	return nil
+}
+
+func (a *API) setupAdminRoutes(mux *http.ServeMux) error {
+	if a.h.admin == nil {
+		return errors.New("admin service is required")
+	}
+	mux.HandleFunc("GET /admin/overview/metrics", a.jwtMiddleware(a.roleMiddleware(types.RoleAdmin, a.h.admin.OverviewMetrics)))
+	mux.HandleFunc("GET /admin/rides/active", a.jwtMiddleware(a.roleMiddleware(types.RoleAdmin, a.h.admin.ActiveRides)))
+	mux.HandleFunc("GET /admin/dlq/{queue}", a.jwtMiddleware(a.roleMiddleware(types.RoleAdmin, a.h.admin.DeadLetters)))
+	mux.HandleFunc("POST /admin/dlq/{queue}/replay", a.jwtMiddleware(a.roleMiddleware(types.RoleAdmin, a.h.admin.ReplayDeadLetters)))
+	mux.HandleFunc("GET /admin/outbox/parked", a.jwtMiddleware(a.roleMiddleware(types.RoleAdmin, a.h.admin.ParkedOutbox)))
+	mux.HandleFunc("POST /admin/outbox/parked/replay", a.jwtMiddleware(a.roleMiddleware(types.RoleAdmin, a.h.admin.ReplayParkedOutbox)))
+	mux.HandleFunc("GET /admin/tariffs", a.jwtMiddleware(a.roleMiddleware(types.RoleAdmin, a.h.admin.Tariffs)))
+	mux.HandleFunc("POST /admin/tariffs", a.jwtMiddleware(a.roleMiddleware(types.RoleAdmin, a.h.admin.CreateTariff)))
 
 	return nil
 }
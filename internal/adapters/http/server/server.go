@@ -22,8 +22,10 @@ type API struct {
 }
 
 type handlers struct {
-	auth handle.AuthHandle
-	ride handle.RideHandler
+	auth  handle.AuthHandle
+	ride  handle.RideHandler
+	dal   handle.DalHandle
+	admin handle.AdminHandle
 }
 
 type Server interface {
@@ -31,10 +33,12 @@ type Server interface {
 	Stop(ctx context.Context) error
 }
 
-func New(cfg config.Config, log *logger.Logger, auth handle.AuthHandle, ride handle.RideHandler) (*API, error) {
+func New(cfg config.Config, log *logger.Logger, auth handle.AuthHandle, ride handle.RideHandler, dal handle.DalHandle, admin handle.AdminHandle) (*API, error) {
 	h := &handlers{
-		auth: auth,
-		ride: ride,
+		auth:  auth,
+		ride:  ride,
+		dal:   dal,
+		admin: admin,
 	}
 
 	api := &API{
@@ -0,0 +1,46 @@
+package websocket
+
+import (
+	"net/http"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/logger"
+)
+
+type DriverWSHandler interface {
+	DriverWebSocketHandler(w http.ResponseWriter, r *http.Request)
+}
+
+type DriverWebSocketHandler struct {
+	manager *DriverWebSocketManager
+	log     *logger.Logger
+}
+
+func NewDriverWebSocketHandler(manager *DriverWebSocketManager, log *logger.Logger) *DriverWebSocketHandler {
+	return &DriverWebSocketHandler{
+		manager: manager,
+		log:     log,
+	}
+}
+
+func (dh *DriverWebSocketHandler) DriverWebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	log := dh.log.Func("DriverWebSocketHandler.DriverWebSocketHandler")
+	ctx := r.Context()
+
+	if logger.GetRole(ctx) != types.RoleDriver {
+		log.Error(ctx, action.WSDriver, "invalid role")
+		writeJSON(w, http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+
+	driverID := r.PathValue("driver_id")
+	if driverID == "" || driverID != logger.GetUserID(ctx) {
+		log.Error(ctx, action.WSDriver, "invalid id")
+		writeJSON(w, http.StatusBadRequest, "invalid driver_id")
+		return
+	}
+
+	log.Info(ctx, "ws_connection_attempt", "driver attempting WebSocket connection")
+
+	dh.manager.HandleDriverConnection(w, r, driverID)
+}
@@ -0,0 +1,342 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"ride-hail/internal/adapters/http/handle/dto"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/internal/core/ports"
+	"ride-hail/pkg/logger"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type DriverWebSocketManager struct {
+	connections map[string]*Driver
+	mu          sync.RWMutex
+	log         *logger.Logger
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+
+	matching ports.MatchingService
+	dal      ports.DalService
+}
+
+type Driver struct {
+	id            string
+	token         string
+	conn          *websocket.Conn
+	authenticated atomic.Bool // читается из горутин matching и dal через send
+	authTimeout   time.Time
+	send          chan []byte
+	lastPing      atomic.Int64 // unix nano последнего pong
+	cancel        context.CancelFunc
+}
+
+type DriverWSMessage struct {
+	Type  string          `json:"type"`
+	Token string          `json:"token,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+type driverWSOutMessage struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+type rideResponseMessage struct {
+	OfferID  string `json:"offer_id"`
+	RideID   string `json:"ride_id"`
+	Accepted bool   `json:"accepted"`
+}
+
+func NewDriverWebSocketManager(ctx context.Context, log *logger.Logger) *DriverWebSocketManager {
+	ctx, cancel := context.WithCancel(ctx)
+	return &DriverWebSocketManager{
+		connections: make(map[string]*Driver),
+		log:         log,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// SetServices подключает сервисы, в которые передаются сообщения от водителей
+func (m *DriverWebSocketManager) SetServices(matching ports.MatchingService, dal ports.DalService) {
+	m.matching = matching
+	m.dal = dal
+}
+
+func (m *DriverWebSocketManager) HandleDriverConnection(w http.ResponseWriter, r *http.Request, driverID string) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		m.log.Func("HandleDriverConnection").Error(r.Context(), action.WSDriver, "upgrade failed", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	ctx = logger.WithUserID(ctx, driverID)
+	driver := &Driver{
+		id:          driverID,
+		token:       logger.GetToken(r.Context()),
+		conn:        conn,
+		send:        make(chan []byte, 10),
+		authTimeout: time.Now().Add(5 * time.Second),
+		cancel:      cancel,
+	}
+
+	m.mu.Lock()
+	if old, ok := m.connections[driverID]; ok {
+		old.cancel()
+	}
+	m.connections[driver.id] = driver
+	m.mu.Unlock()
+
+	m.log.Func("HandleDriverConnection").Info(ctx, action.WSDriver, "new driver connected", "id", driver.id)
+
+	m.wg.Add(2)
+	go m.writePump(ctx, driver)
+	go m.readPump(ctx, driver)
+
+	go func() {
+		<-ctx.Done()
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "server shutdown"))
+		conn.Close()
+	}()
+}
+
+func (m *DriverWebSocketManager) readPump(ctx context.Context, d *Driver) {
+	defer func() {
+		d.cancel()
+		m.removeDriver(d)
+		m.wg.Done()
+	}()
+
+	log := m.log.Func("DriverWebSocketManager.readPump")
+	d.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	d.conn.SetPongHandler(func(string) error {
+		d.lastPing.Store(time.Now().UnixNano())
+		d.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		return nil
+	})
+
+	for {
+		var msg DriverWSMessage
+		if err := d.conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Error(ctx, action.WSDriver, "unexpected close", "error", err)
+			}
+			return
+		}
+
+		if !d.authenticated.Load() {
+			if msg.Type != "auth" {
+				log.Warn(ctx, action.WSDriver, "unauthenticated message", "type", msg.Type)
+				return
+			}
+			if time.Now().After(d.authTimeout) {
+				log.Warn(ctx, action.WSDriver, "auth timeout")
+				return
+			}
+			m.handleAuth(ctx, d, msg)
+			continue
+		}
+
+		m.handleMessage(ctx, d, msg)
+	}
+}
+
+func (m *DriverWebSocketManager) writePump(ctx context.Context, d *Driver) {
+	defer func() {
+		d.cancel()
+		m.wg.Done()
+	}()
+
+	log := m.log.Func("DriverWebSocketManager.writePump")
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug(ctx, action.WSDriver, "context done -> closing writePump")
+			return
+		case msg, ok := <-d.send:
+			if !ok {
+				log.Debug(ctx, action.WSDriver, "send channel closed")
+				return
+			}
+			d.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := d.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				log.Error(ctx, action.WSDriver, "write error", "error", err)
+				return
+			}
+		case <-ticker.C:
+			d.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := d.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				log.Error(ctx, action.WSDriver, "ping error", "error", err)
+				return
+			}
+		}
+	}
+}
+
+func (m *DriverWebSocketManager) handleMessage(ctx context.Context, d *Driver, msg DriverWSMessage) {
+	log := m.log.Func("DriverWebSocketManager.handleMessage")
+
+	switch msg.Type {
+	case "ride_response":
+		m.handleRideResponse(ctx, d, msg)
+	case "location_update":
+		m.handleLocationUpdate(ctx, d, msg)
+	default:
+		log.Warn(ctx, action.WSDriver, "unknown message", "type", msg.Type)
+	}
+}
+
+func (m *DriverWebSocketManager) handleRideResponse(ctx context.Context, d *Driver, msg DriverWSMessage) {
+	log := m.log.Func("DriverWebSocketManager.handleRideResponse")
+
+	var resp rideResponseMessage
+	if err := json.Unmarshal(msg.Data, &resp); err != nil || resp.OfferID == "" || resp.RideID == "" {
+		log.Warn(ctx, action.WSDriver, "invalid ride response", "error", err)
+		m.trySend(ctx, d, driverWSOutMessage{Type: "error", Data: "invalid ride_response"})
+		return
+	}
+
+	if m.matching == nil {
+		log.Error(ctx, action.WSDriver, "matching service is not set")
+		return
+	}
+
+	if err := m.matching.HandleOfferResponse(ctx, d.id, resp.OfferID, resp.RideID, resp.Accepted); err != nil {
+		if errors.Is(err, types.ErrOfferNotFound) {
+			m.trySend(ctx, d, driverWSOutMessage{Type: "error", Data: "ride offer expired"})
+			return
+		}
+		log.Error(ctx, action.WSDriver, "failed to handle ride response", "error", err)
+		return
+	}
+
+	log.Info(ctx, action.WSDriver, "ride response received", "ride_id", resp.RideID, "accepted", resp.Accepted)
+}
+
+func (m *DriverWebSocketManager) handleLocationUpdate(ctx context.Context, d *Driver, msg DriverWSMessage) {
+	log := m.log.Func("DriverWebSocketManager.handleLocationUpdate")
+
+	var loc models.LocationUpdate
+	if err := json.Unmarshal(msg.Data, &loc); err != nil {
+		log.Warn(ctx, action.WSDriver, "invalid location update", "error", err)
+		m.trySend(ctx, d, driverWSOutMessage{Type: "error", Data: "invalid location_update"})
+		return
+	}
+
+	if errMsg := dto.ValidateLocationUpdate(loc); errMsg != "" {
+		m.trySend(ctx, d, driverWSOutMessage{Type: "error", Data: errMsg})
+		return
+	}
+
+	if m.dal == nil {
+		log.Error(ctx, action.WSDriver, "driver service is not set")
+		return
+	}
+
+	if _, err := m.dal.UpdateLocation(ctx, d.id, loc); err != nil {
+		log.Warn(ctx, action.WSDriver, "failed to update location", "error", err)
+		m.trySend(ctx, d, driverWSOutMessage{Type: "error", Data: err.Error()})
+	}
+}
+
+func (m *DriverWebSocketManager) handleAuth(ctx context.Context, d *Driver, msg DriverWSMessage) {
+	log := m.log.Func("DriverWebSocketManager.handleAuth")
+
+	if d.token == "" {
+		m.trySend(ctx, d, driverWSOutMessage{Type: "auth_error", Data: "missing token"})
+		return
+	}
+
+	if d.token != msg.Token {
+		m.trySend(ctx, d, driverWSOutMessage{Type: "auth_error", Data: "invalid token"})
+		return
+	}
+
+	d.authenticated.Store(true)
+	d.authTimeout = time.Time{}
+	log.Info(ctx, action.WSDriver, "authenticated", "id", d.id)
+
+	m.trySend(ctx, d, driverWSOutMessage{Type: "auth_success"})
+}
+
+func (m *DriverWebSocketManager) trySend(ctx context.Context, d *Driver, msg driverWSOutMessage) {
+	data, _ := json.Marshal(msg)
+	select {
+	case d.send <- data:
+	default:
+		m.log.Func("DriverWebSocketManager.trySend").Warn(ctx, action.WSDriver, "send channel full -> closing connection")
+		d.cancel()
+	}
+}
+
+func (m *DriverWebSocketManager) removeDriver(d *Driver) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cur, ok := m.connections[d.id]; ok && cur == d {
+		delete(m.connections, d.id)
+	}
+}
+
+func (m *DriverWebSocketManager) Shutdown() {
+	m.log.Func("DriverWebSocketManager.Shutdown").Info(context.Background(), action.WSDriver, "closing all WS connections")
+	m.cancel()
+
+	m.mu.Lock()
+	for _, d := range m.connections {
+		d.cancel()
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+}
+
+func (m *DriverWebSocketManager) SendRideOffer(ctx context.Context, driverID string, offer models.RideOffer) error {
+	data, err := json.Marshal(driverWSOutMessage{Type: "ride_offer", Data: offer})
+	if err != nil {
+		return fmt.Errorf("failed to marshal ride offer: %w", err)
+	}
+	return m.send(ctx, driverID, data)
+}
+
+// SendRideCancelled сообщает водителю, что ехать к пассажиру больше не нужно
+func (m *DriverWebSocketManager) SendRideCancelled(ctx context.Context, driverID string, event models.DriverReleaseEvent) error {
+	data, err := json.Marshal(driverWSOutMessage{Type: "ride_cancelled", Data: event})
+	if err != nil {
+		return fmt.Errorf("failed to marshal ride cancellation: %w", err)
+	}
+	return m.send(ctx, driverID, data)
+}
+
+func (m *DriverWebSocketManager) send(ctx context.Context, driverID string, data []byte) error {
+	m.mu.RLock()
+	conn, exists := m.connections[driverID]
+	m.mu.RUnlock()
+
+	if !exists || !conn.authenticated.Load() {
+		return fmt.Errorf("driver %s not connected", driverID)
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("timeout sending message to driver %s", driverID)
+	case conn.send <- data:
+		return nil
+	case <-time.After(5 * time.Second):
+		return fmt.Errorf("timeout sending message to driver %s", driverID)
+	}
+}
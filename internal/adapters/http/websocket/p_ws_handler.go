@@ -30,9 +30,9 @@ func (ph *PassengerWebSocketHandler) PassengerWebSocketHandler(w http.ResponseWr
 
 	passengerId := getRideID(r)
 
-	if passengerId == "" || passengerId == logger.GetUserID(ctx) {
+	if passengerId == "" || passengerId != logger.GetUserID(ctx) {
 		log.Error(ctx, action.WSPassenger, "invalid id")
-		writeJSON(w, http.StatusBadRequest, "invalid ride_id")
+		writeJSON(w, http.StatusBadRequest, "invalid passenger_id")
 		return
 	}
 
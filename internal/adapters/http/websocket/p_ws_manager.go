@@ -9,6 +9,7 @@ import (
 	"ride-hail/internal/core/domain/action"
 	"ride-hail/pkg/logger"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,11 +24,12 @@ type PassengerWebSocketManager struct {
 
 type Passenger struct {
 	id            string
+	token         string
 	conn          *websocket.Conn
-	authenticated bool
+	authenticated atomic.Bool // читается из горутин consumer-ов через send
 	authTimeout   time.Time
 	send          chan []byte
-	lastPing      time.Time
+	lastPing      atomic.Int64 // unix nano последнего pong
 	cancel        context.CancelFunc
 }
 
@@ -61,15 +63,18 @@ func (m *PassengerWebSocketManager) HandlePassengerConnection(w http.ResponseWri
 
 	ctx, cancel := context.WithCancel(m.ctx)
 	passenger := &Passenger{
-		id:            passengerID,
-		conn:          conn,
-		authenticated: false,
-		send:          make(chan []byte, 10),
-		authTimeout:   time.Now().Add(5 * time.Second),
-		cancel:        cancel,
+		id:          passengerID,
+		token:       logger.GetToken(r.Context()),
+		conn:        conn,
+		send:        make(chan []byte, 10),
+		authTimeout: time.Now().Add(5 * time.Second),
+		cancel:      cancel,
 	}
 
 	m.mu.Lock()
+	if old, ok := m.connections[passenger.id]; ok {
+		old.cancel()
+	}
 	m.connections[passenger.id] = passenger
 	m.mu.Unlock()
 
@@ -89,14 +94,14 @@ func (m *PassengerWebSocketManager) HandlePassengerConnection(w http.ResponseWri
 func (m *PassengerWebSocketManager) readPump(ctx context.Context, p *Passenger) {
 	defer func() {
 		p.cancel()
-		m.removePassenger(p.id)
+		m.removePassenger(p)
 		m.wg.Done()
 	}()
 
 	log := m.log.Func("PassengerWebSocketManager.readPump")
 	p.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	p.conn.SetPongHandler(func(string) error {
-		p.lastPing = time.Now()
+		p.lastPing.Store(time.Now().UnixNano())
 		p.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 		return nil
 	})
@@ -110,7 +115,7 @@ func (m *PassengerWebSocketManager) readPump(ctx context.Context, p *Passenger)
 			return
 		}
 
-		if !p.authenticated {
+		if !p.authenticated.Load() {
 			if msg.Type != "auth" {
 				log.Warn(ctx, action.WSPassenger, "unauthenticated message", "type", msg.Type)
 				return
@@ -130,7 +135,6 @@ func (m *PassengerWebSocketManager) readPump(ctx context.Context, p *Passenger)
 func (m *PassengerWebSocketManager) writePump(ctx context.Context, p *Passenger) {
 	defer func() {
 		p.cancel()
-		m.removePassenger(p.id)
 		m.wg.Done()
 	}()
 
@@ -149,7 +153,7 @@ func (m *PassengerWebSocketManager) writePump(ctx context.Context, p *Passenger)
 				return
 			}
 			p.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-			if err := p.conn.WriteJSON(msg); err != nil {
+			if err := p.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
 				log.Error(ctx, action.WSPassenger, "write error", "error", err)
 				return
 			}
@@ -174,18 +178,17 @@ func (m *PassengerWebSocketManager) handleMessage(ctx context.Context, p *Passen
 func (m *PassengerWebSocketManager) handleAuth(ctx context.Context, p *Passenger, msg PassengerWSMessage) {
 	log := m.log.Func("handleAuth")
 
-	ctxToken := logger.GetToken(ctx)
-	if ctxToken == "" {
+	if p.token == "" {
 		p.send <- m.marshalMessage(PassengerWSMessage{Type: "auth_error", Data: "missing token"})
 		return
 	}
 
-	if ctxToken != msg.Token {
+	if p.token != msg.Token {
 		p.send <- m.marshalMessage(PassengerWSMessage{Type: "auth_error", Data: "invalid token"})
 		return
 	}
 
-	p.authenticated = true
+	p.authenticated.Store(true)
 	p.authTimeout = time.Time{}
 	log.Info(ctx, action.WSPassenger, "authenticated", "id", p.id)
 
@@ -199,12 +202,11 @@ func (m *PassengerWebSocketManager) handleAuth(ctx context.Context, p *Passenger
 	}
 }
 
-func (m *PassengerWebSocketManager) removePassenger(id string) {
+func (m *PassengerWebSocketManager) removePassenger(p *Passenger) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if p, ok := m.connections[id]; ok {
-		close(p.send)
-		delete(m.connections, id)
+	if cur, ok := m.connections[p.id]; ok && cur == p {
+		delete(m.connections, p.id)
 	}
 }
 
@@ -233,7 +235,7 @@ func (m *PassengerWebSocketManager) SendRide(ctx context.Context, passengerID st
 	conn, exists := m.connections[passengerID]
 	m.mu.RUnlock()
 
-	if !exists || !conn.authenticated {
+	if !exists || !conn.authenticated.Load() {
 		return fmt.Errorf("passenger %s not connected", passengerID)
 	}
 
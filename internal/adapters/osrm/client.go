@@ -0,0 +1,69 @@
+package osrm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"ride-hail/internal/core/domain/models"
+)
+
+// Client ходит в OSRM-совместимый HTTP API (/route/v1/driving); подходит и собственный сервер, и заглушка
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+type routeResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Routes  []struct {
+		Distance float64 `json:"distance"` // метры
+		Duration float64 `json:"duration"` // секунды
+	} `json:"routes"`
+}
+
+func (c *Client) Route(ctx context.Context, from, to models.Position) (models.Route, error) {
+	// OSRM принимает координаты в порядке долгота,широта
+	url := fmt.Sprintf("%s/route/v1/driving/%.6f,%.6f;%.6f,%.6f?overview=false",
+		c.baseURL, from.Longitude, from.Latitude, to.Longitude, to.Latitude)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return models.Route{}, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return models.Route{}, fmt.Errorf("osrm request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body routeResponse
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return models.Route{}, fmt.Errorf("osrm response status %d: %w", resp.StatusCode, err)
+	}
+
+	if resp.StatusCode != http.StatusOK || body.Code != "Ok" {
+		return models.Route{}, fmt.Errorf("osrm response status %d: %s %s", resp.StatusCode, body.Code, body.Message)
+	}
+	if len(body.Routes) == 0 {
+		return models.Route{}, fmt.Errorf("osrm returned no routes")
+	}
+
+	return models.Route{
+		DistanceKm:      body.Routes[0].Distance / 1000,
+		DurationMinutes: int(math.Ceil(body.Routes[0].Duration / 60)),
+	}, nil
+}
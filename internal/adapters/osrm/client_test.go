@@ -0,0 +1,100 @@
+package osrm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ride-hail/internal/core/domain/models"
+)
+
+var (
+	testFrom = models.Position{Latitude: 43.238949, Longitude: 76.889709}
+	testTo   = models.Position{Latitude: 43.222015, Longitude: 76.851248}
+)
+
+func TestClientRoute(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		delay   time.Duration
+		want    models.Route
+		wantErr string
+	}{
+		{
+			name:   "ok",
+			status: http.StatusOK,
+			body:   `{"code":"Ok","routes":[{"distance":5250.4,"duration":601.2}]}`,
+			want:   models.Route{DistanceKm: 5.2504, DurationMinutes: 11},
+		},
+		{
+			name:    "no route",
+			status:  http.StatusBadRequest,
+			body:    `{"code":"NoRoute","message":"Impossible route between points"}`,
+			wantErr: "NoRoute",
+		},
+		{
+			name:    "ok code without routes",
+			status:  http.StatusOK,
+			body:    `{"code":"Ok","routes":[]}`,
+			wantErr: "no routes",
+		},
+		{
+			name:    "server error",
+			status:  http.StatusBadGateway,
+			body:    `<html>bad gateway</html>`,
+			wantErr: "status 502",
+		},
+		{
+			name:    "timeout",
+			status:  http.StatusOK,
+			body:    `{"code":"Ok","routes":[{"distance":1000,"duration":60}]}`,
+			delay:   200 * time.Millisecond,
+			wantErr: "osrm request failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				if tt.delay > 0 {
+					select {
+					case <-time.After(tt.delay):
+					case <-r.Context().Done():
+						return
+					}
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			client := NewClient(srv.URL+"/", 50*time.Millisecond)
+			got, err := client.Route(context.Background(), testFrom, testTo)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Route() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Route() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Route() = %+v, want %+v", got, tt.want)
+			}
+			// долгота идёт первой
+			if want := "/route/v1/driving/76.889709,43.238949;76.851248,43.222015"; path != want {
+				t.Errorf("request path = %q, want %q", path, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/executor"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AdminRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAdminRepository(pool *pgxpool.Pool) *AdminRepository {
+	return &AdminRepository{
+		pool: pool,
+	}
+}
+
+func (repo *AdminRepository) GetRideMetrics(ctx context.Context) (models.Metrics, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT
+		(SELECT count(*) FROM rides WHERE status IN ('MATCHED', 'EN_ROUTE', 'ARRIVED', 'IN_PROGRESS')),
+		count(*),
+		COALESCE(sum(final_fare) FILTER (WHERE status = 'COMPLETED'), 0)::float8,
+		COALESCE(avg(EXTRACT(EPOCH FROM matched_at - requested_at) / 60) FILTER (WHERE matched_at IS NOT NULL), 0)::float8,
+		COALESCE(avg(EXTRACT(EPOCH FROM completed_at - started_at) / 60) FILTER (WHERE status = 'COMPLETED'), 0)::float8,
+		COALESCE(count(*) FILTER (WHERE status = 'CANCELLED')::float8 / NULLIF(count(*), 0), 0)::float8
+	FROM rides
+	WHERE requested_at >= date_trunc('day', now())
+	`
+
+	var m models.Metrics
+	if err := ex.QueryRow(ctx, query).Scan(
+		&m.ActiveRides,
+		&m.TotalRidesToday,
+		&m.TotalRevenueToday,
+		&m.AverageWaitTimeMinutes,
+		&m.AverageRideDurationMinutes,
+		&m.CancellationRate,
+	); err != nil {
+		return models.Metrics{}, fmt.Errorf("failed to get ride metrics: %w", err)
+	}
+
+	return m, nil
+}
+
+// GetOnlineDrivers — водители с открытой сессией, по статусу и типу машины
+func (repo *AdminRepository) GetOnlineDrivers(ctx context.Context) (map[string]int, map[string]int, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT d.status, COALESCE(d.vehicle_type, ''), count(*)
+	FROM drivers d
+	JOIN driver_sessions s ON s.driver_id = d.id AND s.ended_at IS NULL
+	WHERE d.status <> $1
+	GROUP BY 1, 2
+	`
+
+	rows, err := ex.Query(ctx, query, types.DriverStatusOffline)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get online drivers: %w", err)
+	}
+	defer rows.Close()
+
+	byStatus := make(map[string]int)
+	byVehicle := make(map[string]int)
+	for rows.Next() {
+		var (
+			status, vehicle string
+			count           int
+		)
+		if err = rows.Scan(&status, &vehicle, &count); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan online drivers: %w", err)
+		}
+		byStatus[status] += count
+		byVehicle[vehicle] += count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to iterate online drivers: %w", err)
+	}
+
+	return byStatus, byVehicle, nil
+}
+
+func (repo *AdminRepository) GetActiveRides(ctx context.Context, limit, offset int) ([]models.ActiveRide, int, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT
+		r.id, r.ride_number, r.status, r.passenger_id, r.driver_id, COALESCE(r.vehicle_type, ''),
+		COALESCE(p.address, ''), COALESCE(d.address, ''),
+		COALESCE(r.estimated_fare, 0)::float8, r.requested_at, r.started_at,
+		count(*) OVER ()
+	FROM rides r
+	LEFT JOIN coordinates p ON p.id = r.pickup_coordinate_id
+	LEFT JOIN coordinates d ON d.id = r.destination_coordinate_id
+	WHERE r.status IN ('REQUESTED', 'MATCHED', 'EN_ROUTE', 'ARRIVED', 'IN_PROGRESS')
+	ORDER BY r.requested_at DESC
+	LIMIT $1 OFFSET $2
+	`
+
+	rows, err := ex.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get active rides: %w", err)
+	}
+	defer rows.Close()
+
+	var total int
+	rides := make([]models.ActiveRide, 0, limit)
+	for rows.Next() {
+		var (
+			r        models.ActiveRide
+			driverID *string
+		)
+		if err = rows.Scan(
+			&r.RideID, &r.RideNumber, &r.Status, &r.PassengerID, &driverID, &r.VehicleType,
+			&r.PickupAddress, &r.DestinationAddress,
+			&r.EstimatedFare, &r.RequestedAt, &r.StartedAt,
+			&total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan active ride: %w", err)
+		}
+		r.DriverID = deref(driverID)
+		rides = append(rides, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate active rides: %w", err)
+	}
+
+	// страница за пределами выборки — общее число берём отдельным запросом
+	if len(rides) == 0 && offset > 0 {
+		if err = ex.QueryRow(ctx, `SELECT count(*) FROM rides WHERE status IN ('REQUESTED', 'MATCHED', 'EN_ROUTE', 'ARRIVED', 'IN_PROGRESS')`).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count active rides: %w", err)
+		}
+	}
+
+	return rides, total, nil
+}
@@ -76,3 +76,47 @@ WHERE id = $1
 
 	return coordinate, err
 }
+
+func (repo *CordRepository) ResetCurrentCoordinates(ctx context.Context, entityID, entityType string) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `UPDATE coordinates
+SET is_current = false,
+    updated_at = now()
+WHERE entity_id = $1 AND entity_type = $2 AND is_current = true
+`
+	if _, err := ex.Exec(ctx, query, entityID, entityType); err != nil {
+		return fmt.Errorf("failed to reset current coordinates: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateCurrentCoordinate двигает текущую точку сущности, создавая её при отсутствии
+func (repo *CordRepository) UpdateCurrentCoordinate(ctx context.Context, entityID, entityType string, lat, lng float64) (string, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `UPDATE coordinates
+SET latitude = $1,
+    longitude = $2,
+    updated_at = now()
+WHERE entity_id = $3 AND entity_type = $4 AND is_current = true
+RETURNING id
+`
+	var id string
+	err := ex.QueryRow(ctx, query, lat, lng, entityID, entityType).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("failed to update current coordinate: %w", err)
+	}
+
+	return repo.CreateNewCoordinate(ctx, models.Coordinate{
+		EntityID:   entityID,
+		EntityType: entityType,
+		Latitude:   lat,
+		Longitude:  lng,
+		IsCurrent:  true,
+	})
+}
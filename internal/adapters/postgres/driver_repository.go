@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
 	"ride-hail/pkg/executor"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -65,7 +67,7 @@ func (r *DriverRepository) Get(ctx context.Context, id string) (models.Driver, e
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return models.Driver{}, fmt.Errorf("driver not found: %w", err)
+			return models.Driver{}, types.ErrDriverNotFound
 		}
 		return models.Driver{}, fmt.Errorf("failed to get driver: %w", err)
 	}
@@ -135,21 +137,26 @@ func (r *DriverRepository) GetLastActiveSession(ctx context.Context, driverID st
 	`
 
 	var session models.DriverSession
+	var endedAt *time.Time
 	err := ex.QueryRow(ctx, query, driverID).Scan(
 		&session.ID,
 		&session.DriverID,
 		&session.StartedAt,
-		&session.EndedAt,
+		&endedAt,
 		&session.TotalRides,
 		&session.TotalEarnings,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return models.DriverSession{}, fmt.Errorf("no sessions found for driver %s", driverID)
+			return models.DriverSession{}, types.ErrSessionNotFound
 		}
 		return models.DriverSession{}, err
 	}
 
+	if endedAt != nil {
+		session.EndedAt = *endedAt
+	}
+
 	return session, nil
 }
 
@@ -173,3 +180,193 @@ func (r *DriverRepository) CloseSession(ctx context.Context, id string) error {
 
 	return nil
 }
+
+func (r *DriverRepository) UpdateStatusFrom(ctx context.Context, id, from, to string) error {
+	ex := executor.GetExecutor(ctx, r.pool)
+
+	query := `UPDATE drivers SET status = $1, updated_at = now() WHERE id = $2 AND status = $3`
+	cmdTag, err := ex.Exec(ctx, query, to, id, from)
+	if err != nil {
+		return fmt.Errorf("failed to update driver status: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return types.ErrDriverStatusNotAllow
+	}
+
+	return nil
+}
+
+// FindAvailableNearby ищет свободных водителей в радиусе; ST_DWithin идёт по выражению
+// индекса idx_coordinates_current_geography, поэтому выражение точки менять только вместе с миграцией
+func (r *DriverRepository) FindAvailableNearby(ctx context.Context, search models.DriverSearch) ([]models.DriverCandidate, error) {
+	ex := executor.GetExecutor(ctx, r.pool)
+
+	query := `
+		SELECT d.id, COALESCE(u.attrs->>'name', ''), d.rating, d.vehicle_attrs,
+		       c.latitude, c.longitude, COALESCE(c.address, ''),
+		       ST_Distance(
+		           ST_SetSRID(ST_MakePoint(c.longitude, c.latitude), 4326)::geography,
+		           ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography
+		       ) / 1000 AS distance_km
+		FROM drivers d
+		JOIN users u ON u.id = d.id
+		JOIN coordinates c ON c.entity_id = d.id
+		     AND c.entity_type = 'driver'
+		     AND c.is_current = true
+		WHERE d.status = 'AVAILABLE'
+		  AND d.vehicle_type = $3
+		  AND ST_DWithin(
+		        ST_SetSRID(ST_MakePoint(c.longitude, c.latitude), 4326)::geography,
+		        ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography,
+		        $4 * 1000
+		  )
+		ORDER BY distance_km ASC, d.rating DESC
+		LIMIT $5
+	`
+
+	rows, err := ex.Query(ctx, query, search.Longitude, search.Latitude, search.VehicleType, search.RadiusKm, search.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find available drivers: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []models.DriverCandidate
+	for rows.Next() {
+		var c models.DriverCandidate
+		var attrs []byte
+		if err = rows.Scan(
+			&c.DriverID,
+			&c.Name,
+			&c.Rating,
+			&attrs,
+			&c.Location.Lat,
+			&c.Location.Lng,
+			&c.Location.Address,
+			&c.DistanceKm,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan driver candidate: %w", err)
+		}
+
+		if len(attrs) > 0 {
+			if err = json.Unmarshal(attrs, &c.VehicleAttrs); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal driver attrs: %w", err)
+			}
+		}
+		candidates = append(candidates, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate driver candidates: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// CreditRide засчитывает водителю завершённую поездку и её стоимость, в том числе в открытой смене
+func (r *DriverRepository) CreditRide(ctx context.Context, id string, fare float64) error {
+	ex := executor.GetExecutor(ctx, r.pool)
+
+	query := `
+		UPDATE drivers
+		SET total_rides = total_rides + 1,
+		    total_earnings = total_earnings + $1,
+		    updated_at = now()
+		WHERE id = $2
+	`
+
+	cmdTag, err := ex.Exec(ctx, query, fare, id)
+	if err != nil {
+		return fmt.Errorf("failed to credit driver: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return types.ErrDriverNotFound
+	}
+
+	// смены может не быть, если водитель ушёл в офлайн во время поездки
+	query = `
+		UPDATE driver_sessions
+		SET total_rides = total_rides + 1,
+		    total_earnings = total_earnings + $1
+		WHERE id = (
+			SELECT id FROM driver_sessions
+			WHERE driver_id = $2 AND ended_at IS NULL
+			ORDER BY started_at DESC
+			LIMIT 1
+		)
+	`
+
+	if _, err = ex.Exec(ctx, query, fare, id); err != nil {
+		return fmt.Errorf("failed to credit driver session: %w", err)
+	}
+
+	return nil
+}
+
+// SumEarnings считает завершённые поездки водителя и их итоговую стоимость за [from, to)
+func (r *DriverRepository) SumEarnings(ctx context.Context, driverID string, from, to time.Time) (int, float64, error) {
+	ex := executor.GetExecutor(ctx, r.pool)
+
+	query := `
+		SELECT count(*), COALESCE(sum(COALESCE(final_fare, estimated_fare)), 0)::float8
+		FROM rides
+		WHERE driver_id = $1 AND status = $2 AND completed_at >= $3 AND completed_at < $4
+	`
+
+	var (
+		rides    int
+		earnings float64
+	)
+	if err := ex.QueryRow(ctx, query, driverID, types.RideStatusCOMPLETED, from, to).Scan(&rides, &earnings); err != nil {
+		return 0, 0, fmt.Errorf("failed to sum driver earnings: %w", err)
+	}
+
+	return rides, earnings, nil
+}
+
+// ListSessions отдаёт смены водителя, пересекающиеся с [from, to), от новых к старым.
+// TotalRides и TotalEarnings считаются только по поездкам смены, завершённым внутри [from, to),
+// а не берутся из накопленных итогов driver_sessions.
+func (r *DriverRepository) ListSessions(ctx context.Context, driverID string, from, to time.Time) ([]models.DriverSession, error) {
+	ex := executor.GetExecutor(ctx, r.pool)
+
+	query := `
+		SELECT s.id, s.driver_id, s.started_at, s.ended_at, e.rides, e.earnings
+		FROM driver_sessions s
+		CROSS JOIN LATERAL (
+			SELECT count(*) AS rides, COALESCE(sum(COALESCE(rd.final_fare, rd.estimated_fare)), 0)::float8 AS earnings
+			FROM rides rd
+			WHERE rd.driver_id = s.driver_id
+			  AND rd.status = $4
+			  AND rd.completed_at >= GREATEST(s.started_at, $2)
+			  AND rd.completed_at < $3
+			  AND (s.ended_at IS NULL OR rd.completed_at <= s.ended_at)
+		) e
+		WHERE s.driver_id = $1 AND s.started_at < $3 AND (s.ended_at IS NULL OR s.ended_at >= $2)
+		ORDER BY s.started_at DESC
+	`
+
+	rows, err := ex.Query(ctx, query, driverID, from, to, types.RideStatusCOMPLETED)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list driver sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.DriverSession
+	for rows.Next() {
+		var (
+			s       models.DriverSession
+			endedAt *time.Time
+		)
+		if err = rows.Scan(&s.ID, &s.DriverID, &s.StartedAt, &endedAt, &s.TotalRides, &s.TotalEarnings); err != nil {
+			return nil, fmt.Errorf("failed to scan driver session: %w", err)
+		}
+		if endedAt != nil {
+			s.EndedAt = *endedAt
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/pkg/executor"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LocationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLocationRepository(pool *pgxpool.Pool) *LocationRepository {
+	return &LocationRepository{
+		pool: pool,
+	}
+}
+
+func (repo *LocationRepository) Insert(ctx context.Context, l models.LocationHistory) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `INSERT INTO location_history
+		(coordinate_id, driver_id, latitude, longitude, accuracy_meters,
+		speed_kmh, heading_degrees, recorded_at, ride_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, '')::uuid)`
+
+	_, err := ex.Exec(
+		ctx, query,
+		l.CoordinateID, l.DriverID, l.Latitude, l.Longitude, l.AccuracyMeters,
+		l.SpeedKmh, l.HeadingDegrees, l.RecordedAt, l.RideID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert location history: %w", err)
+	}
+
+	return nil
+}
+
+// ListByRide возвращает точки поездки за период по порядку записи
+func (repo *LocationRepository) ListByRide(ctx context.Context, rideID string, from, to time.Time) ([]models.LocationHistory, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT id, COALESCE(coordinate_id::text, ''), driver_id, latitude, longitude,
+	       COALESCE(accuracy_meters, 0), COALESCE(speed_kmh, 0), COALESCE(heading_degrees, 0),
+	       recorded_at, ride_id
+	FROM location_history
+	WHERE ride_id = $1 AND recorded_at BETWEEN $2 AND $3
+	ORDER BY recorded_at
+	`
+
+	rows, err := ex.Query(ctx, query, rideID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ride locations: %w", err)
+	}
+	defer rows.Close()
+
+	var points []models.LocationHistory
+	for rows.Next() {
+		var l models.LocationHistory
+		if err = rows.Scan(
+			&l.ID,
+			&l.CoordinateID,
+			&l.DriverID,
+			&l.Latitude,
+			&l.Longitude,
+			&l.AccuracyMeters,
+			&l.SpeedKmh,
+			&l.HeadingDegrees,
+			&l.RecordedAt,
+			&l.RideID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ride location: %w", err)
+		}
+		points = append(points, l)
+	}
+
+	return points, rows.Err()
+}
@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/pkg/executor"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOutboxRepository(pool *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{
+		pool: pool,
+	}
+}
+
+func (repo *OutboxRepository) Insert(ctx context.Context, m models.OutboxMessage) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	INSERT INTO outbox (exchange, routing_key, payload, correlation_id)
+	VALUES ($1, $2, $3, NULLIF($4, ''))
+	`
+
+	if _, err := ex.Exec(ctx, query, m.Exchange, m.RoutingKey, m.Payload, m.CorrelationID); err != nil {
+		return fmt.Errorf("failed to insert outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimPending забирает сообщения, для которых подошло время попытки, и откладывает их до leaseUntil.
+// Блокировка держится только на время запроса: пока relay публикует, другие их не возьмут,
+// а если relay упадёт, сообщения вернутся в работу по истечении аренды
+func (repo *OutboxRepository) ClaimPending(ctx context.Context, limit int, leaseUntil time.Time) ([]models.OutboxMessage, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	WITH claimed AS (
+		SELECT id
+		FROM outbox
+		WHERE sent_at IS NULL AND parked_at IS NULL AND next_attempt_at <= now()
+		ORDER BY created_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	UPDATE outbox o SET next_attempt_at = $2
+	FROM claimed
+	WHERE o.id = claimed.id
+	RETURNING o.id, o.created_at, o.exchange, o.routing_key, o.payload, COALESCE(o.correlation_id, ''), o.attempts, o.rejections
+	`
+
+	rows, err := ex.Query(ctx, query, limit, leaseUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]models.OutboxMessage, 0, limit)
+	for rows.Next() {
+		var m models.OutboxMessage
+		if err = rows.Scan(&m.ID, &m.CreatedAt, &m.Exchange, &m.RoutingKey, &m.Payload, &m.CorrelationID, &m.Attempts, &m.Rejections); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate outbox messages: %w", err)
+	}
+
+	// RETURNING не сохраняет порядок подзапроса
+	slices.SortFunc(messages, func(a, b models.OutboxMessage) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return messages, nil
+}
+
+func (repo *OutboxRepository) MarkSent(ctx context.Context, id string) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	if _, err := ex.Exec(ctx, `UPDATE outbox SET sent_at = now(), last_error = NULL WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to mark outbox message sent: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed откладывает следующую попытку до nextAttemptAt
+func (repo *OutboxRepository) MarkFailed(ctx context.Context, id, reason string, nextAttemptAt time.Time) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE id = $1`
+	if _, err := ex.Exec(ctx, query, id, reason, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to mark outbox message failed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkRejected — брокер ответил nack; такие попытки, в отличие от ошибок соединения, ведут к парковке
+func (repo *OutboxRepository) MarkRejected(ctx context.Context, id, reason string, nextAttemptAt time.Time) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `UPDATE outbox SET attempts = attempts + 1, rejections = rejections + 1, last_error = $2, next_attempt_at = $3 WHERE id = $1`
+	if _, err := ex.Exec(ctx, query, id, reason, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to mark outbox message rejected: %w", err)
+	}
+
+	return nil
+}
+
+// Park снимает сообщение с отправки; оно остаётся в таблице с причиной для разбора
+func (repo *OutboxRepository) Park(ctx context.Context, id, reason string) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = $2, parked_at = now() WHERE id = $1`
+	if _, err := ex.Exec(ctx, query, id, reason); err != nil {
+		return fmt.Errorf("failed to park outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// ListParked отдаёт запаркованные сообщения от старых к новым
+func (repo *OutboxRepository) ListParked(ctx context.Context, limit int) ([]models.ParkedMessage, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT id, created_at, parked_at, exchange, routing_key, COALESCE(correlation_id, ''), attempts, COALESCE(last_error, ''), payload::text
+	FROM outbox
+	WHERE parked_at IS NOT NULL AND sent_at IS NULL
+	ORDER BY created_at
+	LIMIT $1
+	`
+
+	rows, err := ex.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list parked outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]models.ParkedMessage, 0, limit)
+	for rows.Next() {
+		var m models.ParkedMessage
+		if err = rows.Scan(&m.ID, &m.CreatedAt, &m.ParkedAt, &m.Exchange, &m.RoutingKey, &m.CorrelationID, &m.Attempts, &m.LastError, &m.Payload); err != nil {
+			return nil, fmt.Errorf("failed to scan parked outbox message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate parked outbox messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// Unpark возвращает до limit самых старых запаркованных сообщений в отправку со сброшенными счётчиками
+func (repo *OutboxRepository) Unpark(ctx context.Context, limit int) (int, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	UPDATE outbox SET parked_at = NULL, attempts = 0, rejections = 0, next_attempt_at = now()
+	WHERE id IN (
+		SELECT id
+		FROM outbox
+		WHERE parked_at IS NOT NULL AND sent_at IS NULL
+		ORDER BY created_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	`
+
+	tag, err := ex.Exec(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unpark outbox messages: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ride-hail/internal/core/domain/models"
+)
+
+func TestOutboxRepositoryParkAndUnpark(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+	repo := NewOutboxRepository(pool)
+
+	routingKey := "test.parked." + time.Now().Format("150405.000000")
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM outbox WHERE routing_key = $1`, routingKey)
+	})
+
+	if err := repo.Insert(ctx, models.OutboxMessage{Exchange: "test", RoutingKey: routingKey, Payload: []byte(`{}`)}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	var id string
+	if err := pool.QueryRow(ctx, `SELECT id FROM outbox WHERE routing_key = $1`, routingKey).Scan(&id); err != nil {
+		t.Fatalf("select id: %v", err)
+	}
+	if err := repo.Park(ctx, id, "unroutable"); err != nil {
+		t.Fatalf("Park: %v", err)
+	}
+
+	parked, err := repo.ListParked(ctx, 1000)
+	if err != nil {
+		t.Fatalf("ListParked: %v", err)
+	}
+	found := false
+	for _, m := range parked {
+		if m.ID == id {
+			found = true
+			if m.LastError != "unroutable" || m.RoutingKey != routingKey {
+				t.Errorf("parked message = %+v", m)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("message %s is not listed as parked", id)
+	}
+
+	if _, err = repo.Unpark(ctx, 1000); err != nil {
+		t.Fatalf("Unpark: %v", err)
+	}
+
+	var parkedAt *time.Time
+	var attempts int
+	if err = pool.QueryRow(ctx, `SELECT parked_at, attempts FROM outbox WHERE id = $1`, id).Scan(&parkedAt, &attempts); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if parkedAt != nil || attempts != 0 {
+		t.Errorf("after Unpark parked_at = %v, attempts = %d, want nil and 0", parkedAt, attempts)
+	}
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPool подключается к базе с применёнными миграциями из TEST_DATABASE_DSN;
+// без переменной тесты репозиториев пропускаются
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if err = pool.Ping(context.Background()); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+
+	return pool
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/executor"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RatingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRatingRepository(pool *pgxpool.Pool) *RatingRepository {
+	return &RatingRepository{
+		pool: pool,
+	}
+}
+
+func (repo *RatingRepository) Insert(ctx context.Context, r models.RideRating) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	INSERT INTO ride_ratings (ride_id, rater_id, ratee_id, rater_role, score, comment, tags)
+	VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), COALESCE($7::text[], '{}'))
+	`
+
+	if _, err := ex.Exec(ctx, query, r.RideID, r.RaterID, r.RateeID, r.RaterRole, r.Score, r.Comment, r.Tags); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return types.ErrAlreadyRated
+		}
+		return fmt.Errorf("failed to insert ride rating: %w", err)
+	}
+
+	return nil
+}
+
+// LockRatee блокирует пользователя до конца транзакции, чтобы параллельные оценки
+// пересчитывали среднее по очереди и не затирали друг друга
+func (repo *RatingRepository) LockRatee(ctx context.Context, rateeID string) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	var id string
+	if err := ex.QueryRow(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, rateeID).Scan(&id); err != nil {
+		return fmt.Errorf("failed to lock rated user: %w", err)
+	}
+
+	return nil
+}
+
+// WeightedScores отдаёт сумму взвешенных оценок, сумму весов и число оценок по последним window оценкам;
+// самая новая весит 1, каждая следующая в decay раз меньше
+func (repo *RatingRepository) WeightedScores(ctx context.Context, rateeID string, window int, decay float64) (float64, float64, int, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	WITH recent AS (
+		SELECT score, row_number() OVER (ORDER BY created_at DESC, id) - 1 AS n
+		FROM ride_ratings
+		WHERE ratee_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	)
+	SELECT COALESCE(sum(score * power($3::float8, n)), 0)::float8,
+	       COALESCE(sum(power($3::float8, n)), 0)::float8,
+	       count(*)
+	FROM recent
+	`
+
+	var (
+		scores, weights float64
+		count           int
+	)
+	if err := ex.QueryRow(ctx, query, rateeID, window, decay).Scan(&scores, &weights, &count); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to aggregate ratings: %w", err)
+	}
+
+	return scores, weights, count, nil
+}
+
+func (repo *RatingRepository) SetDriverRating(ctx context.Context, driverID string, rating float64) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	cmdTag, err := ex.Exec(ctx, `UPDATE drivers SET rating = $1, updated_at = now() WHERE id = $2`, rating, driverID)
+	if err != nil {
+		return fmt.Errorf("failed to update driver rating: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return types.ErrDriverNotFound
+	}
+
+	return nil
+}
+
+// SetPassengerRating хранит оценку пассажира и число оценок в users.attrs
+func (repo *RatingRepository) SetPassengerRating(ctx context.Context, passengerID string, rating float64, count int) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	UPDATE users
+	SET attrs = COALESCE(attrs, '{}'::jsonb) || jsonb_build_object('rating', $1::numeric, 'rating_count', $2::int),
+	    updated_at = now()
+	WHERE id = $3
+	`
+
+	cmdTag, err := ex.Exec(ctx, query, rating, count, passengerID)
+	if err != nil {
+		return fmt.Errorf("failed to update passenger rating: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return types.ErrUserNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/pkg/executor"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RideEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRideEventRepository(pool *pgxpool.Pool) *RideEventRepository {
+	return &RideEventRepository{
+		pool: pool,
+	}
+}
+
+func (repo *RideEventRepository) Insert(ctx context.Context, rideID, eventType string, data models.RideEventData) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ride event data: %w", err)
+	}
+
+	query := `INSERT INTO ride_events (ride_id, event_type, event_data) VALUES ($1, $2, $3)`
+	if _, err = ex.Exec(ctx, query, rideID, eventType, payload); err != nil {
+		return fmt.Errorf("failed to insert ride event: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *RideEventRepository) ListByRide(ctx context.Context, rideID string) ([]models.RideEvent, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT id, created_at, ride_id, event_type, event_data
+	FROM ride_events
+	WHERE ride_id = $1
+	ORDER BY created_at, id
+	`
+
+	rows, err := ex.Query(ctx, query, rideID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ride events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]models.RideEvent, 0)
+	for rows.Next() {
+		var e models.RideEvent
+		if err = rows.Scan(&e.ID, &e.CreatedAt, &e.RideID, &e.EventType, &e.EventData); err != nil {
+			return nil, fmt.Errorf("failed to scan ride event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ride events: %w", err)
+	}
+
+	return events, nil
+}
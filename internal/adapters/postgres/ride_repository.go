@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"ride-hail/internal/core/domain/models"
@@ -28,10 +29,15 @@ func (repo *RideRepository) CreateNewRide(ctx context.Context, ride models.Ride)
 
 	query := `INSERT INTO rides (
 		ride_number, passenger_id, vehicle_type, status,
-		estimated_fare, pickup_coordinate_id, destination_coordinate_id
-	) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		estimated_fare, pickup_coordinate_id, destination_coordinate_id, tariff_id, surge_multiplier, scheduled_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, '')::uuid, GREATEST($9, 1.0), $10)
 	RETURNING id`
 
+	var scheduledAt *time.Time
+	if !ride.ScheduledAt.IsZero() {
+		scheduledAt = &ride.ScheduledAt
+	}
+
 	var id string
 	err := ex.QueryRow(
 		ctx, query,
@@ -42,6 +48,9 @@ func (repo *RideRepository) CreateNewRide(ctx context.Context, ride models.Ride)
 		ride.EstimatedFare,
 		ride.PickupCoordinateId,
 		ride.DestinationCoordinateId,
+		ride.TariffID,
+		ride.SurgeMultiplier,
+		scheduledAt,
 	).Scan(&id)
 	if err != nil {
 		return "", fmt.Errorf("failed to create ride: %w", err)
@@ -57,12 +66,17 @@ func (repo *RideRepository) GetRide(ctx context.Context, id string) (models.Ride
 	SELECT id, created_at, updated_at, ride_number, passenger_id, driver_id, vehicle_type,
 	       status, priority, requested_at, matched_at, arrived_at, started_at,
 	       completed_at, cancelled_at, cancellation_reason, estimated_fare, final_fare,
-	       pickup_coordinate_id, destination_coordinate_id
+	       pickup_coordinate_id, destination_coordinate_id, tariff_id, surge_multiplier, scheduled_at
 	FROM rides
 	WHERE id = $1
 	`
 
-	var ride models.Ride
+	var (
+		ride                                                                   models.Ride
+		driverID, cancellationReason, tariffID                                 *string
+		matchedAt, arrivedAt, startedAt, completedAt, cancelledAt, scheduledAt *time.Time
+		finalFare                                                              *float64
+	)
 
 	err := ex.QueryRow(ctx, query, id).Scan(
 		&ride.ID,
@@ -70,21 +84,24 @@ func (repo *RideRepository) GetRide(ctx context.Context, id string) (models.Ride
 		&ride.UpdatedAt,
 		&ride.RideNumber,
 		&ride.PassengerID,
-		&ride.DriverID,
+		&driverID,
 		&ride.VehicleType,
 		&ride.Status,
 		&ride.Priority,
 		&ride.RequestedAt,
-		&ride.MatchedAt,
-		&ride.ArrivedAt,
-		&ride.StartedAt,
-		&ride.CompletedAt,
-		&ride.CancelledAt,
-		&ride.CancellationReason,
+		&matchedAt,
+		&arrivedAt,
+		&startedAt,
+		&completedAt,
+		&cancelledAt,
+		&cancellationReason,
 		&ride.EstimatedFare,
-		&ride.FinalFare,
+		&finalFare,
 		&ride.PickupCoordinateId,
 		&ride.DestinationCoordinateId,
+		&tariffID,
+		&ride.SurgeMultiplier,
+		&scheduledAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -93,6 +110,17 @@ func (repo *RideRepository) GetRide(ctx context.Context, id string) (models.Ride
 		return models.Ride{}, fmt.Errorf("failed to get ride by id %s: %w", id, err)
 	}
 
+	ride.DriverID = deref(driverID)
+	ride.CancellationReason = deref(cancellationReason)
+	ride.FinalFare = deref(finalFare)
+	ride.TariffID = deref(tariffID)
+	ride.MatchedAt = deref(matchedAt)
+	ride.ArrivedAt = deref(arrivedAt)
+	ride.StartedAt = deref(startedAt)
+	ride.CompletedAt = deref(completedAt)
+	ride.CancelledAt = deref(cancelledAt)
+	ride.ScheduledAt = deref(scheduledAt)
+
 	return ride, nil
 }
 
@@ -114,15 +142,14 @@ func (repo *RideRepository) GenerateRideNumber(ctx context.Context) (int, error)
 	return counter, nil
 }
 
-func (repo *RideRepository) UpdateRide(ctx context.Context, rideID string, newStatus string, reason string, t *time.Time) error {
+// UpdateRide переводит поездку в newStatus только если её текущий статус равен expected
+func (repo *RideRepository) UpdateRide(ctx context.Context, rideID, expected, newStatus, reason string, t *time.Time) error {
 	ex := executor.GetExecutor(ctx, repo.pool)
 
 	var timeField string
 	switch newStatus {
 	case types.RideStatusMATCHED:
 		timeField = "matched_at"
-	case types.RideStatusEN_ROUTE:
-		timeField = "arrived_at"
 	case types.RideStatusARRIVED:
 		timeField = "arrived_at"
 	case types.RideStatusIN_PROGRESS:
@@ -145,9 +172,9 @@ func (repo *RideRepository) UpdateRide(ctx context.Context, rideID string, newSt
 					%s = COALESCE(%s, $2),
 					cancellation_reason = CASE WHEN $3 != '' THEN $3 ELSE cancellation_reason END,
 					updated_at = now()
-				WHERE id = $4
+				WHERE id = $4 AND status = $5
 			`, timeField, timeField)
-			args = []any{newStatus, t, reason, rideID}
+			args = []any{newStatus, t, reason, rideID, expected}
 		} else {
 			query = fmt.Sprintf(`
 				UPDATE rides
@@ -155,9 +182,9 @@ func (repo *RideRepository) UpdateRide(ctx context.Context, rideID string, newSt
 					status = $1,
 					%s = COALESCE(%s, $2),
 					updated_at = now()
-				WHERE id = $3
+				WHERE id = $3 AND status = $4
 			`, timeField, timeField)
-			args = []any{newStatus, t, rideID}
+			args = []any{newStatus, t, rideID, expected}
 		}
 	} else {
 		query = `
@@ -165,16 +192,20 @@ func (repo *RideRepository) UpdateRide(ctx context.Context, rideID string, newSt
 			SET 
 				status = $1,
 				updated_at = now()
-			WHERE id = $2
+			WHERE id = $2 AND status = $3
 		`
-		args = []any{newStatus, rideID}
+		args = []any{newStatus, rideID, expected}
 	}
 
-	_, err := ex.Exec(ctx, query, args...)
+	cmdTag, err := ex.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update ride status: %w", err)
 	}
 
+	if cmdTag.RowsAffected() == 0 {
+		return types.ErrRideStatusConflict
+	}
+
 	return nil
 }
 
@@ -184,15 +215,402 @@ func (r *RideRepository) UpdateMatchedRide(ctx context.Context, rideID, driverID
 	query := `
 UPDATE rides
 SET driver_id = $1,
-    matched_at = $2,
+    status = $2,
+    matched_at = $3,
     updated_at = now()
-WHERE id = $3
+WHERE id = $4 AND status = $5
 `
 
-	_, err := ex.Exec(ctx, query, driverID, matchedAt, rideID)
+	cmdTag, err := ex.Exec(ctx, query, driverID, types.RideStatusMATCHED, matchedAt, rideID, types.RideStatusREQUESTED)
 	if err != nil {
 		return err
 	}
 
+	if cmdTag.RowsAffected() == 0 {
+		return types.ErrRideStatusConflict
+	}
+
+	return nil
+}
+
+// deref возвращает нулевое значение для NULL-колонок
+func deref[T any](v *T) T {
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return *v
+}
+
+func (repo *RideRepository) GetActiveRideID(ctx context.Context, driverID string) (string, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT id
+	FROM rides
+	WHERE driver_id = $1 AND status IN ($2, $3, $4, $5)
+	ORDER BY matched_at DESC NULLS LAST
+	LIMIT 1
+	`
+
+	var id string
+	err := ex.QueryRow(ctx, query, driverID,
+		types.RideStatusMATCHED,
+		types.RideStatusEN_ROUTE,
+		types.RideStatusARRIVED,
+		types.RideStatusIN_PROGRESS,
+	).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", types.ErrRideNotFound
+		}
+		return "", fmt.Errorf("failed to get active ride for driver %s: %w", driverID, err)
+	}
+
+	return id, nil
+}
+
+func (repo *RideRepository) SetFinalFare(ctx context.Context, rideID string, fare float64) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `UPDATE rides SET final_fare = $1, updated_at = now() WHERE id = $2`
+	cmdTag, err := ex.Exec(ctx, query, fare, rideID)
+	if err != nil {
+		return fmt.Errorf("failed to set final fare: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return types.ErrRideNotFound
+	}
+
+	return nil
+}
+
+// ListExpiredDispatches блокирует до limit поездок в REQUESTED, опубликованных раньше before;
+// SKIP LOCKED не даёт двум планировщикам взять одну поездку
+func (repo *RideRepository) ListExpiredDispatches(ctx context.Context, before time.Time, limit int) ([]models.RideDispatch, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT id, ride_number, passenger_id, vehicle_type, status, estimated_fare,
+	       pickup_coordinate_id, destination_coordinate_id, dispatch_attempts
+	FROM rides
+	WHERE status = $1 AND dispatched_at < $2
+	ORDER BY dispatched_at
+	LIMIT $3
+	FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := ex.Query(ctx, query, types.RideStatusREQUESTED, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired dispatches: %w", err)
+	}
+	defer rows.Close()
+
+	var dispatches []models.RideDispatch
+	for rows.Next() {
+		var d models.RideDispatch
+		if err = rows.Scan(
+			&d.Ride.ID,
+			&d.Ride.RideNumber,
+			&d.Ride.PassengerID,
+			&d.Ride.VehicleType,
+			&d.Ride.Status,
+			&d.Ride.EstimatedFare,
+			&d.Ride.PickupCoordinateId,
+			&d.Ride.DestinationCoordinateId,
+			&d.Attempts,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan expired dispatch: %w", err)
+		}
+		dispatches = append(dispatches, d)
+	}
+
+	return dispatches, rows.Err()
+}
+
+// MarkRedispatched засчитывает повторную публикацию запроса и перезапускает ожидание
+func (repo *RideRepository) MarkRedispatched(ctx context.Context, rideID string) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	UPDATE rides
+	SET dispatch_attempts = dispatch_attempts + 1,
+	    dispatched_at = now(),
+	    updated_at = now()
+	WHERE id = $1 AND status = $2
+	`
+
+	cmdTag, err := ex.Exec(ctx, query, rideID, types.RideStatusREQUESTED)
+	if err != nil {
+		return fmt.Errorf("failed to mark ride redispatched: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return types.ErrRideStatusConflict
+	}
+
 	return nil
 }
+
+// ListDueScheduled блокирует до limit запланированных поездок с подачей раньше before
+func (repo *RideRepository) ListDueScheduled(ctx context.Context, before time.Time, limit int) ([]models.Ride, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT id, ride_number, passenger_id, vehicle_type, status, estimated_fare,
+	       pickup_coordinate_id, destination_coordinate_id, scheduled_at
+	FROM rides
+	WHERE status = $1 AND scheduled_at <= $2
+	ORDER BY scheduled_at
+	LIMIT $3
+	FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := ex.Query(ctx, query, types.RideStatusSCHEDULED, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due scheduled rides: %w", err)
+	}
+	defer rows.Close()
+
+	var rides []models.Ride
+	for rows.Next() {
+		var r models.Ride
+		if err = rows.Scan(
+			&r.ID,
+			&r.RideNumber,
+			&r.PassengerID,
+			&r.VehicleType,
+			&r.Status,
+			&r.EstimatedFare,
+			&r.PickupCoordinateId,
+			&r.DestinationCoordinateId,
+			&r.ScheduledAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled ride: %w", err)
+		}
+		rides = append(rides, r)
+	}
+
+	return rides, rows.Err()
+}
+
+// PromoteScheduled переводит запланированную поездку в REQUESTED и запускает ожидание водителя с начала
+func (repo *RideRepository) PromoteScheduled(ctx context.Context, rideID string, requestedAt time.Time) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	UPDATE rides
+	SET status = $1,
+	    requested_at = $2,
+	    dispatched_at = $2,
+	    dispatch_attempts = 0,
+	    updated_at = now()
+	WHERE id = $3 AND status = $4
+	`
+
+	cmdTag, err := ex.Exec(ctx, query, types.RideStatusREQUESTED, requestedAt, rideID, types.RideStatusSCHEDULED)
+	if err != nil {
+		return fmt.Errorf("failed to promote scheduled ride: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return types.ErrRideStatusConflict
+	}
+
+	return nil
+}
+
+// UpdateScheduled сохраняет новое время подачи, адреса и цену, пока поездка ещё в SCHEDULED
+func (repo *RideRepository) UpdateScheduled(ctx context.Context, ride models.Ride) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	UPDATE rides
+	SET scheduled_at = $1,
+	    pickup_coordinate_id = $2,
+	    destination_coordinate_id = $3,
+	    estimated_fare = $4,
+	    tariff_id = NULLIF($5, '')::uuid,
+	    updated_at = now()
+	WHERE id = $6 AND status = $7
+	`
+
+	cmdTag, err := ex.Exec(ctx, query,
+		ride.ScheduledAt,
+		ride.PickupCoordinateId,
+		ride.DestinationCoordinateId,
+		ride.EstimatedFare,
+		ride.TariffID,
+		ride.ID,
+		types.RideStatusSCHEDULED,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled ride: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return types.ErrRideStatusConflict
+	}
+
+	return nil
+}
+
+// ListByPassenger отдаёт до filter.Limit поездок пассажира, начиная сразу после after, от новых к старым
+func (repo *RideRepository) ListByPassenger(ctx context.Context, filter models.RideFilter, after *models.RideCursor) ([]models.RideSummary, error) {
+	return repo.listRides(ctx, "passenger_id", filter.PassengerID, filter, after)
+}
+
+// ListByDriver — то же для поездок водителя
+func (repo *RideRepository) ListByDriver(ctx context.Context, filter models.RideFilter, after *models.RideCursor) ([]models.RideSummary, error) {
+	return repo.listRides(ctx, "driver_id", filter.DriverID, filter, after)
+}
+
+// listRides — column задаётся только кодом выше, не из запроса.
+// id сравнивается как текст: для uuid в нижнем регистре порядок тот же, а чужой курсор не ломает запрос
+func (repo *RideRepository) listRides(ctx context.Context, column, ownerID string, filter models.RideFilter, after *models.RideCursor) ([]models.RideSummary, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT r.id, r.ride_number, r.status, COALESCE(r.vehicle_type, ''),
+	       COALESCE(p.address, ''), COALESCE(d.address, ''),
+	       COALESCE(r.estimated_fare, 0)::float8, r.final_fare::float8, COALESCE(t.currency, 'KZT'),
+	       r.requested_at, r.scheduled_at, r.completed_at, r.cancelled_at
+	FROM rides r
+	LEFT JOIN coordinates p ON p.id = r.pickup_coordinate_id
+	LEFT JOIN coordinates d ON d.id = r.destination_coordinate_id
+	LEFT JOIN tariffs t ON t.id = r.tariff_id
+	WHERE r.` + column + ` = $1
+	  AND ($2::text[] IS NULL OR r.status::text = ANY($2))
+	  AND ($3::timestamptz IS NULL OR r.requested_at >= $3)
+	  AND ($4::timestamptz IS NULL OR r.requested_at < $4)
+	  AND ($5::timestamptz IS NULL OR (r.requested_at, r.id::text) < ($5, $6::text))
+	ORDER BY r.requested_at DESC, r.id DESC
+	LIMIT $7
+	`
+
+	var (
+		afterAt *time.Time
+		afterID string
+	)
+	if after != nil {
+		afterAt, afterID = &after.RequestedAt, after.RideID
+	}
+
+	rows, err := ex.Query(ctx, query, ownerID, filter.Statuses, filter.From, filter.To, afterAt, afterID, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rides by %s: %w", column, err)
+	}
+	defer rows.Close()
+
+	rides := make([]models.RideSummary, 0, filter.Limit)
+	for rows.Next() {
+		var r models.RideSummary
+		if err = rows.Scan(
+			&r.RideID,
+			&r.RideNumber,
+			&r.Status,
+			&r.VehicleType,
+			&r.PickupAddress,
+			&r.DestinationAddress,
+			&r.EstimatedFare,
+			&r.FinalFare,
+			&r.Currency,
+			&r.RequestedAt,
+			&r.ScheduledAt,
+			&r.CompletedAt,
+			&r.CancelledAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ride: %w", err)
+		}
+		rides = append(rides, r)
+	}
+
+	return rides, rows.Err()
+}
+
+// GetDetail собирает поездку с адресами, водителем и валютой тарифа; проверка владельца остаётся сервису
+func (repo *RideRepository) GetDetail(ctx context.Context, rideID string) (models.RideDetail, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT r.id, r.ride_number, r.passenger_id, r.status, COALESCE(r.vehicle_type, ''),
+	       p.latitude, p.longitude, COALESCE(p.address, ''),
+	       d.latitude, d.longitude, COALESCE(d.address, ''),
+	       r.driver_id, COALESCE(u.attrs->>'name', ''), COALESCE(dr.rating, 0)::float8, dr.vehicle_attrs,
+	       COALESCE(r.estimated_fare, 0)::float8, r.final_fare::float8, COALESCE(t.currency, 'KZT'),
+	       COALESCE(r.surge_multiplier, 1)::float8,
+	       r.requested_at, r.scheduled_at, r.matched_at, r.arrived_at, r.started_at,
+	       r.completed_at, r.cancelled_at, r.cancellation_reason
+	FROM rides r
+	JOIN coordinates p ON p.id = r.pickup_coordinate_id
+	JOIN coordinates d ON d.id = r.destination_coordinate_id
+	LEFT JOIN drivers dr ON dr.id = r.driver_id
+	LEFT JOIN users u ON u.id = r.driver_id
+	LEFT JOIN tariffs t ON t.id = r.tariff_id
+	WHERE r.id = $1
+	`
+
+	var (
+		ride             models.RideDetail
+		driverID, reason *string
+		driverName       string
+		driverRating     float64
+		vehicleAttrs     []byte
+	)
+
+	err := ex.QueryRow(ctx, query, rideID).Scan(
+		&ride.RideID,
+		&ride.RideNumber,
+		&ride.PassengerID,
+		&ride.Status,
+		&ride.VehicleType,
+		&ride.PickupLocation.Lat,
+		&ride.PickupLocation.Lng,
+		&ride.PickupLocation.Address,
+		&ride.DestinationLocation.Lat,
+		&ride.DestinationLocation.Lng,
+		&ride.DestinationLocation.Address,
+		&driverID,
+		&driverName,
+		&driverRating,
+		&vehicleAttrs,
+		&ride.EstimatedFare,
+		&ride.FinalFare,
+		&ride.Currency,
+		&ride.SurgeMultiplier,
+		&ride.RequestedAt,
+		&ride.ScheduledAt,
+		&ride.MatchedAt,
+		&ride.ArrivedAt,
+		&ride.StartedAt,
+		&ride.CompletedAt,
+		&ride.CancelledAt,
+		&reason,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.RideDetail{}, types.ErrRideNotFound
+		}
+		return models.RideDetail{}, fmt.Errorf("failed to get ride detail %s: %w", rideID, err)
+	}
+
+	ride.CancellationReason = deref(reason)
+
+	if driverID != nil {
+		driver := &models.RideDriver{DriverID: *driverID, Name: driverName, Rating: driverRating}
+		if len(vehicleAttrs) > 0 {
+			var attrs models.VehicleAttrs
+			if err = json.Unmarshal(vehicleAttrs, &attrs); err != nil {
+				return models.RideDetail{}, fmt.Errorf("failed to decode vehicle attrs: %w", err)
+			}
+			driver.Vehicle.Make = attrs.Make
+			driver.Vehicle.Model = attrs.Model
+			driver.Vehicle.Color = attrs.Color
+			driver.Vehicle.Plate = attrs.LicensePlate
+		}
+		ride.Driver = driver
+	}
+
+	return ride, nil
+}
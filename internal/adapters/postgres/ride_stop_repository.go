@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/executor"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RideStopRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRideStopRepository(pool *pgxpool.Pool) *RideStopRepository {
+	return &RideStopRepository{
+		pool: pool,
+	}
+}
+
+func (repo *RideStopRepository) Insert(ctx context.Context, rideID string, seq int, coordinateID string) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `INSERT INTO ride_stops (ride_id, seq, coordinate_id) VALUES ($1, $2, $3)`
+	if _, err := ex.Exec(ctx, query, rideID, seq, coordinateID); err != nil {
+		return fmt.Errorf("failed to insert ride stop: %w", err)
+	}
+
+	return nil
+}
+
+// ListByRide возвращает остановки поездки в порядке объезда
+func (repo *RideStopRepository) ListByRide(ctx context.Context, rideID string) ([]models.RideStop, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT s.seq, s.coordinate_id, c.latitude, c.longitude, c.address, s.reached_at
+	FROM ride_stops s
+	JOIN coordinates c ON c.id = s.coordinate_id
+	WHERE s.ride_id = $1
+	ORDER BY s.seq
+	`
+
+	rows, err := ex.Query(ctx, query, rideID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ride stops: %w", err)
+	}
+	defer rows.Close()
+
+	var stops []models.RideStop
+	for rows.Next() {
+		var s models.RideStop
+		if err = rows.Scan(
+			&s.Seq,
+			&s.CoordinateID,
+			&s.Location.Lat,
+			&s.Location.Lng,
+			&s.Location.Address,
+			&s.ReachedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ride stop: %w", err)
+		}
+		stops = append(stops, s)
+	}
+
+	return stops, rows.Err()
+}
+
+// MarkReached отмечает остановку пройденной; повторная отметка не меняет время
+func (repo *RideStopRepository) MarkReached(ctx context.Context, rideID string, seq int, at time.Time) error {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `UPDATE ride_stops SET reached_at = COALESCE(reached_at, $1) WHERE ride_id = $2 AND seq = $3`
+	cmdTag, err := ex.Exec(ctx, query, at, rideID, seq)
+	if err != nil {
+		return fmt.Errorf("failed to mark ride stop reached: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return types.ErrStopNotFound
+	}
+
+	return nil
+}
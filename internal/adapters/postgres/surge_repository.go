@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/executor"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SurgeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSurgeRepository(pool *pgxpool.Pool) *SurgeRepository {
+	return &SurgeRepository{
+		pool: pool,
+	}
+}
+
+// ZoneSupplyDemand считает в геохеш-зоне открытые заказы и свободных водителей, активных с since.
+// Кандидаты отбираются по индексу idx_coordinates_point через рамку ячейки, точное совпадение
+// геохеша проверяется уже на них — точки на общей границе соседних ячеек попадают в обе рамки.
+func (repo *SurgeRepository) ZoneSupplyDemand(ctx context.Context, zone string, since time.Time) (int, int, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT
+		(SELECT count(*)
+		 FROM rides r
+		 JOIN coordinates c ON c.id = r.pickup_coordinate_id
+		 WHERE r.status = $2
+		   AND r.requested_at >= $3
+		   AND ST_SetSRID(ST_MakePoint(c.longitude, c.latitude), 4326) && ST_SetSRID(ST_GeomFromGeoHash($1), 4326)
+		   AND ST_GeoHash(ST_SetSRID(ST_MakePoint(c.longitude, c.latitude), 4326), length($1)) = $1),
+		(SELECT count(*)
+		 FROM drivers d
+		 JOIN coordinates c ON c.entity_id = d.id
+		      AND c.entity_type = 'driver'
+		      AND c.is_current = true
+		 WHERE d.status = $4
+		   AND c.updated_at >= $3
+		   AND ST_SetSRID(ST_MakePoint(c.longitude, c.latitude), 4326) && ST_SetSRID(ST_GeomFromGeoHash($1), 4326)
+		   AND ST_GeoHash(ST_SetSRID(ST_MakePoint(c.longitude, c.latitude), 4326), length($1)) = $1)
+	`
+
+	var demand, supply int
+	err := ex.QueryRow(ctx, query, zone, types.RideStatusREQUESTED, since, types.DriverStatusAvailable).Scan(&demand, &supply)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count zone supply and demand: %w", err)
+	}
+
+	return demand, supply, nil
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/pkg/executor"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TariffRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTariffRepository(pool *pgxpool.Pool) *TariffRepository {
+	return &TariffRepository{
+		pool: pool,
+	}
+}
+
+// List возвращает все версии тарифов, новые версии каждого типа идут первыми
+func (repo *TariffRepository) List(ctx context.Context) ([]models.Tariff, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	SELECT id, created_at, vehicle_type, base_fare, rate_per_km, rate_per_min,
+	       minimum_fare, booking_fee, free_waiting_minutes, waiting_rate_per_min, currency, effective_from
+	FROM tariffs
+	ORDER BY vehicle_type, effective_from DESC, created_at DESC
+	`
+
+	rows, err := ex.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tariffs: %w", err)
+	}
+	defer rows.Close()
+
+	var tariffs []models.Tariff
+	for rows.Next() {
+		var t models.Tariff
+		if err = rows.Scan(
+			&t.ID,
+			&t.CreatedAt,
+			&t.VehicleType,
+			&t.BaseFare,
+			&t.RatePerKm,
+			&t.RatePerMin,
+			&t.MinimumFare,
+			&t.BookingFee,
+			&t.FreeWaitingMinutes,
+			&t.WaitingRatePerMin,
+			&t.Currency,
+			&t.EffectiveFrom,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan tariff: %w", err)
+		}
+		tariffs = append(tariffs, t)
+	}
+
+	return tariffs, rows.Err()
+}
+
+func (repo *TariffRepository) Insert(ctx context.Context, t models.Tariff) (models.Tariff, error) {
+	ex := executor.GetExecutor(ctx, repo.pool)
+
+	query := `
+	INSERT INTO tariffs (
+		vehicle_type, base_fare, rate_per_km, rate_per_min,
+		minimum_fare, booking_fee, free_waiting_minutes, waiting_rate_per_min, currency, effective_from
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	RETURNING id, created_at
+	`
+
+	err := ex.QueryRow(
+		ctx, query,
+		t.VehicleType,
+		t.BaseFare,
+		t.RatePerKm,
+		t.RatePerMin,
+		t.MinimumFare,
+		t.BookingFee,
+		t.FreeWaitingMinutes,
+		t.WaitingRatePerMin,
+		t.Currency,
+		t.EffectiveFrom,
+	).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return models.Tariff{}, fmt.Errorf("failed to insert tariff: %w", err)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ride-hail/internal/core/domain/types"
+)
+
+func TestTariffRepositoryListSeeded(t *testing.T) {
+	repo := NewTariffRepository(testPool(t))
+
+	tariffs, err := repo.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	now := time.Now()
+	for _, rideType := range types.RideTypes {
+		found := false
+		for _, tariff := range tariffs {
+			if tariff.VehicleType == rideType && !tariff.EffectiveFrom.After(now) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("no tariff in effect for %s", rideType)
+		}
+	}
+}
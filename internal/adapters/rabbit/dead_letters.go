@@ -0,0 +1,53 @@
+package rabbit
+
+import (
+	"context"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/rabbit"
+)
+
+type DeadLetters struct {
+	rabbit *rabbit.Rabbit
+}
+
+func NewDeadLetters(r *rabbit.Rabbit) *DeadLetters {
+	return &DeadLetters{
+		rabbit: r,
+	}
+}
+
+func (d *DeadLetters) Peek(ctx context.Context, queue string, limit int) ([]models.DeadLetter, error) {
+	if !isKnownQueue(queue) {
+		return nil, types.ErrQueueNotFound
+	}
+
+	letters, err := d.rabbit.PeekDeadLetters(queue, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]models.DeadLetter, 0, len(letters))
+	for _, l := range letters {
+		res = append(res, models.DeadLetter{
+			MessageID:     l.MessageID,
+			CorrelationID: l.CorrelationID,
+			Queue:         queue,
+			RoutingKey:    l.RoutingKey,
+			Reason:        l.Reason,
+			RetryCount:    l.RetryCount,
+			Timestamp:     l.Timestamp,
+			Body:          string(l.Body),
+		})
+	}
+
+	return res, nil
+}
+
+func (d *DeadLetters) Replay(ctx context.Context, queue string, limit int) (int, error) {
+	if !isKnownQueue(queue) {
+		return 0, types.ErrQueueNotFound
+	}
+
+	return d.rabbit.ReplayDeadLetters(ctx, queue, limit)
+}
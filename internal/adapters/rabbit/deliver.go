@@ -0,0 +1,20 @@
+package rabbit
+
+import (
+	"context"
+	"ride-hail/internal/core/domain/models"
+)
+
+// deliver передаёт событие сервису и ждёт обработки. Пока сервис занят, обработчик блокируется,
+// поэтому новые сообщения не выбираются из очереди сверх prefetch
+func deliver[T any](ctx context.Context, ch chan<- models.Delivery[T], event T) error {
+	d := models.NewDelivery(event)
+
+	select {
+	case ch <- d:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return d.Wait(ctx)
+}
@@ -3,14 +3,14 @@ package rabbit
 import (
 	"context"
 	"encoding/json"
-	amqp "github.com/rabbitmq/amqp091-go"
+	"fmt"
 	"ride-hail/internal/core/domain/models"
 	"ride-hail/pkg/rabbit"
 )
 
 type DriverResponseConsumer struct {
 	consumer *rabbit.Consumer
-	ch       chan models.DriverResponseEvent
+	ch       chan models.Delivery[models.DriverResponseEvent]
 }
 
 const (
@@ -18,22 +18,18 @@ const (
 	driverResponseQueue    = "driver_responses"
 )
 
-func NewDriverResponseConsumer(conn *amqp.Connection) *DriverResponseConsumer {
-	ch := make(chan models.DriverResponseEvent, 100)
+func NewDriverResponseConsumer(r *rabbit.Rabbit, opts rabbit.ConsumerOptions) *DriverResponseConsumer {
+	ch := make(chan models.Delivery[models.DriverResponseEvent])
 
-	c := rabbit.NewConsumer(conn, driverResponseExchange, driverResponseQueue)
+	c := rabbit.NewConsumer(r, driverResponseExchange, driverResponseQueue, opts)
 
 	c.SetHandler(rabbit.MessageHandlerFunc(func(ctx context.Context, msg []byte, rk string) error {
 		var response models.DriverResponseEvent
 		if err := json.Unmarshal(msg, &response); err != nil {
-			return nil
+			return fmt.Errorf("%w: failed to unmarshal driver response: %v", rabbit.ErrPoisonMessage, err)
 		}
 
-		select {
-		case ch <- response:
-		default:
-		}
-		return nil
+		return deliver(ctx, ch, response)
 	}))
 
 	return &DriverResponseConsumer{
@@ -46,6 +42,10 @@ func (r *DriverResponseConsumer) Start(ctx context.Context) error {
 	return r.consumer.StartConsuming(ctx)
 }
 
-func (r *DriverResponseConsumer) Subscribe(ctx context.Context) (<-chan models.DriverResponseEvent, error) {
+func (r *DriverResponseConsumer) Stop() {
+	r.consumer.Stop()
+}
+
+func (r *DriverResponseConsumer) Subscribe(ctx context.Context) (<-chan models.Delivery[models.DriverResponseEvent], error) {
 	return r.ch, nil
 }
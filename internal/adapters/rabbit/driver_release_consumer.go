@@ -0,0 +1,51 @@
+package rabbit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/pkg/rabbit"
+)
+
+type DriverReleaseConsumer struct {
+	consumer *rabbit.Consumer
+	ch       chan models.Delivery[models.DriverReleaseEvent]
+}
+
+const (
+	driverReleaseExchange = "driver_topic"
+	driverReleaseQueue    = "driver_status"
+)
+
+func NewDriverReleaseConsumer(r *rabbit.Rabbit, opts rabbit.ConsumerOptions) *DriverReleaseConsumer {
+	ch := make(chan models.Delivery[models.DriverReleaseEvent])
+
+	c := rabbit.NewConsumer(r, driverReleaseExchange, driverReleaseQueue, opts)
+
+	c.SetHandler(rabbit.MessageHandlerFunc(func(ctx context.Context, msg []byte, rk string) error {
+		var event models.DriverReleaseEvent
+		if err := json.Unmarshal(msg, &event); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal driver release event: %v", rabbit.ErrPoisonMessage, err)
+		}
+
+		return deliver(ctx, ch, event)
+	}))
+
+	return &DriverReleaseConsumer{
+		consumer: c,
+		ch:       ch,
+	}
+}
+
+func (r *DriverReleaseConsumer) Start(ctx context.Context) error {
+	return r.consumer.StartConsuming(ctx)
+}
+
+func (r *DriverReleaseConsumer) Stop() {
+	r.consumer.Stop()
+}
+
+func (r *DriverReleaseConsumer) Subscribe(ctx context.Context) (<-chan models.Delivery[models.DriverReleaseEvent], error) {
+	return r.ch, nil
+}
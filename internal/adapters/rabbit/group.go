@@ -0,0 +1,43 @@
+package rabbit
+
+import (
+	"context"
+	"fmt"
+)
+
+type groupMember interface {
+	Start(ctx context.Context) error
+	Stop()
+}
+
+// Group запускает и останавливает консьюмеров приложения вместе
+type Group struct {
+	members []groupMember
+	started []groupMember
+}
+
+func NewGroup(members ...groupMember) *Group {
+	return &Group{
+		members: members,
+	}
+}
+
+// Start запускает всех консьюмеров; если один не стартовал, уже запущенные останавливаются
+func (g *Group) Start(ctx context.Context) error {
+	for _, m := range g.members {
+		if err := m.Start(ctx); err != nil {
+			g.Stop()
+			return fmt.Errorf("failed to start consumer %T: %w", m, err)
+		}
+		g.started = append(g.started, m)
+	}
+	return nil
+}
+
+// Stop останавливает запущенных консьюмеров и ждёт завершения обработки текущих сообщений
+func (g *Group) Stop() {
+	for i := len(g.started) - 1; i >= 0; i-- {
+		g.started[i].Stop()
+	}
+	g.started = nil
+}
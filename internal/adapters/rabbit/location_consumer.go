@@ -3,15 +3,14 @@ package rabbit
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"ride-hail/internal/core/domain/models"
 	"ride-hail/pkg/rabbit"
-
-	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 type LocationConsumer struct {
 	consumer *rabbit.Consumer
-	ch       chan models.DriverLocationUpdate
+	ch       chan models.Delivery[models.DriverLocationUpdate]
 }
 
 const (
@@ -19,19 +18,15 @@ const (
 	queueName = "location_updates_ride"
 )
 
-func NewLocationConsumer(conn *amqp.Connection) *LocationConsumer {
-	ch := make(chan models.DriverLocationUpdate, 100)
-	c := rabbit.NewConsumer(conn, exName, queueName)
+func NewLocationConsumer(r *rabbit.Rabbit, opts rabbit.ConsumerOptions) *LocationConsumer {
+	ch := make(chan models.Delivery[models.DriverLocationUpdate])
+	c := rabbit.NewConsumer(r, exName, queueName, opts)
 	c.SetHandler(rabbit.MessageHandlerFunc(func(ctx context.Context, msg []byte, rk string) error {
 		var loc models.DriverLocationUpdate
 		if err := json.Unmarshal(msg, &loc); err != nil {
-			return nil
-		}
-		select {
-		case ch <- loc:
-		default:
+			return fmt.Errorf("%w: failed to unmarshal driver location: %v", rabbit.ErrPoisonMessage, err)
 		}
-		return nil
+		return deliver(ctx, ch, loc)
 	}))
 	return &LocationConsumer{consumer: c, ch: ch}
 }
@@ -40,6 +35,10 @@ func (r *LocationConsumer) Start(ctx context.Context) error {
 	return r.consumer.StartConsuming(ctx)
 }
 
-func (r *LocationConsumer) Subscribe(ctx context.Context) (<-chan models.DriverLocationUpdate, error) {
+func (r *LocationConsumer) Stop() {
+	r.consumer.Stop()
+}
+
+func (r *LocationConsumer) Subscribe(ctx context.Context) (<-chan models.Delivery[models.DriverLocationUpdate], error) {
 	return r.ch, nil
 }
@@ -0,0 +1,51 @@
+package rabbit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/pkg/rabbit"
+)
+
+type RideRequestConsumer struct {
+	consumer *rabbit.Consumer
+	ch       chan models.Delivery[models.RideRequestRideType]
+}
+
+const (
+	rideRequestExchange = "ride_topic"
+	rideRequestQueue    = "ride_requests"
+)
+
+func NewRideRequestConsumer(r *rabbit.Rabbit, opts rabbit.ConsumerOptions) *RideRequestConsumer {
+	ch := make(chan models.Delivery[models.RideRequestRideType])
+
+	c := rabbit.NewConsumer(r, rideRequestExchange, rideRequestQueue, opts)
+
+	c.SetHandler(rabbit.MessageHandlerFunc(func(ctx context.Context, msg []byte, rk string) error {
+		var request models.RideRequestRideType
+		if err := json.Unmarshal(msg, &request); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal ride request: %v", rabbit.ErrPoisonMessage, err)
+		}
+
+		return deliver(ctx, ch, request)
+	}))
+
+	return &RideRequestConsumer{
+		consumer: c,
+		ch:       ch,
+	}
+}
+
+func (r *RideRequestConsumer) Start(ctx context.Context) error {
+	return r.consumer.StartConsuming(ctx)
+}
+
+func (r *RideRequestConsumer) Stop() {
+	r.consumer.Stop()
+}
+
+func (r *RideRequestConsumer) Subscribe(ctx context.Context) (<-chan models.Delivery[models.RideRequestRideType], error) {
+	return r.ch, nil
+}
@@ -4,39 +4,32 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	amqp "github.com/rabbitmq/amqp091-go"
 	"ride-hail/internal/core/domain/models"
 	"ride-hail/pkg/rabbit"
 )
 
 type RideStatusConsumer struct {
 	consumer *rabbit.Consumer
-	ch       chan models.RideStatusEvent
+	ch       chan models.Delivery[models.RideStatusEvent]
 }
 
 const (
 	rideStatusExchange = "ride_topic"
-	rideStatusQueue    = "ride_statuses"
+	rideStatusQueue    = "ride_status"
 )
 
-func NewRideStatusConsumer(conn *amqp.Connection) *RideStatusConsumer {
-	ch := make(chan models.RideStatusEvent, 100)
+func NewRideStatusConsumer(r *rabbit.Rabbit, opts rabbit.ConsumerOptions) *RideStatusConsumer {
+	ch := make(chan models.Delivery[models.RideStatusEvent])
 
-	c := rabbit.NewConsumer(conn, rideStatusExchange, rideStatusQueue)
+	c := rabbit.NewConsumer(r, rideStatusExchange, rideStatusQueue, opts)
 
 	c.SetHandler(rabbit.MessageHandlerFunc(func(ctx context.Context, msg []byte, rk string) error {
 		var event models.RideStatusEvent
 		if err := json.Unmarshal(msg, &event); err != nil {
-			fmt.Printf("failed to unmarshal ride status event: %v\n", err)
-			return nil
+			return fmt.Errorf("%w: failed to unmarshal ride status event: %v", rabbit.ErrPoisonMessage, err)
 		}
 
-		select {
-		case ch <- event:
-		default:
-			fmt.Println("ride status channel full, dropping message")
-		}
-		return nil
+		return deliver(ctx, ch, event)
 	}))
 
 	return &RideStatusConsumer{
@@ -49,6 +42,10 @@ func (r *RideStatusConsumer) Start(ctx context.Context) error {
 	return r.consumer.StartConsuming(ctx)
 }
 
-func (r *RideStatusConsumer) Subscribe(ctx context.Context) (<-chan models.RideStatusEvent, error) {
+func (r *RideStatusConsumer) Stop() {
+	r.consumer.Stop()
+}
+
+func (r *RideStatusConsumer) Subscribe(ctx context.Context) (<-chan models.Delivery[models.RideStatusEvent], error) {
 	return r.ch, nil
 }
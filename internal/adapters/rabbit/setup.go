@@ -5,8 +5,23 @@ import (
 	"ride-hail/pkg/rabbit"
 )
 
+var (
+	rideQueues = []rabbit.QueueConfig{
+		{Name: "ride_requests", RoutingKey: "ride.request.*"},
+		{Name: "ride_status", RoutingKey: "ride.status.*"},
+	}
+	driverQueues = []rabbit.QueueConfig{
+		{Name: "driver_matching", RoutingKey: "driver.request.*"},
+		{Name: "driver_responses", RoutingKey: "driver.response.*"},
+		{Name: "driver_status", RoutingKey: "driver.status.*"},
+	}
+	locationQueues = []rabbit.QueueConfig{
+		{Name: "location_updates_ride", RoutingKey: ""},
+	}
+)
+
 func InitRabbitTopology(r *rabbit.Rabbit) error {
-	if r.Conn.IsClosed() {
+	if r.Connection().IsClosed() {
 		return errors.New("connection is closed")
 	}
 
@@ -25,19 +40,6 @@ func InitRabbitTopology(r *rabbit.Rabbit) error {
 		}
 	}
 
-	rideQueues := []rabbit.QueueConfig{
-		{"ride_requests", "ride.request.*"},
-		{"ride_status", "ride.status.*"},
-	}
-	driverQueues := []rabbit.QueueConfig{
-		{"driver_matching", "driver.request.*"},
-		{"driver_responses", "driver.response.*"},
-		{"driver_status", "driver.status.*"},
-	}
-	locationQueues := []rabbit.QueueConfig{
-		{"location_updates_ride", ""},
-	}
-
 	if err := r.SetupExchangesAndQueues(exchanges[0].Name, exchanges[0].Type, rideQueues); err != nil {
 		return err
 	}
@@ -50,3 +52,15 @@ func InitRabbitTopology(r *rabbit.Rabbit) error {
 
 	return nil
 }
+
+// isKnownQueue — очередь объявлена в топологии, значит у неё есть DLQ
+func isKnownQueue(name string) bool {
+	for _, queues := range [][]rabbit.QueueConfig{rideQueues, driverQueues, locationQueues} {
+		for _, q := range queues {
+			if q.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
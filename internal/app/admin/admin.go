@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"context"
+	"ride-hail/internal/adapters/http/handle"
+	"ride-hail/internal/adapters/http/server"
+	"ride-hail/internal/adapters/postgres"
+	rabbit2 "ride-hail/internal/adapters/rabbit"
+	"ride-hail/internal/core/service"
+	"ride-hail/pkg/logger"
+	"ride-hail/pkg/rabbit"
+
+	"ride-hail/config"
+	pg "ride-hail/pkg/potgres"
+)
+
+type AdminService struct {
+	server server.Server
+	db     *pg.Postgres
+	rb     *rabbit.Rabbit
+}
+
+func New(ctx context.Context, log *logger.Logger, cfg config.Config) (*AdminService, error) {
+	p, err := pg.New(ctx, cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	uRepo := postgres.NewRepo(p.Pool)
+	aRepo := postgres.NewAdminRepository(p.Pool)
+	tRepo := postgres.NewTariffRepository(p.Pool)
+	oRepo := postgres.NewOutboxRepository(p.Pool)
+
+	rb, err := rabbit.New(cfg.RabbitMQ, log)
+	if err != nil {
+		p.Pool.Close()
+		return nil, err
+	}
+
+	if err = rb.SetTopology(rabbit2.InitRabbitTopology); err != nil {
+		rb.Close()
+		p.Pool.Close()
+		return nil, err
+	}
+
+	dlq := rabbit2.NewDeadLetters(rb)
+
+	authServ := service.NewAuthService(cfg, uRepo, log)
+	adminServ := service.NewAdminService(log, aRepo, tRepo, dlq, oRepo)
+
+	authHandle := handle.New(cfg, authServ, log)
+	adminHandle := handle.NewAdminHandler(adminServ, log)
+
+	serv, err := server.New(cfg, log, authHandle, nil, nil, adminHandle)
+	if err != nil {
+		rb.Close()
+		p.Pool.Close()
+		return nil, err
+	}
+
+	return &AdminService{
+		server: serv,
+		db:     p,
+		rb:     rb,
+	}, nil
+}
+
+func (a *AdminService) Run() error {
+	go a.server.Run()
+	return nil
+}
+
+func (a *AdminService) Stop(ctx context.Context) error {
+	if err := a.server.Stop(ctx); err != nil {
+		return err
+	}
+
+	a.rb.Close()
+	a.db.Pool.Close()
+	return nil
+}
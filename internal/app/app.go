@@ -11,13 +11,15 @@ import (
 	"time"
 
 	"ride-hail/config"
+	"ride-hail/internal/app/admin"
+	dal "ride-hail/internal/app/drive"
 	"ride-hail/internal/app/ride"
 	"ride-hail/internal/core/domain/action"
 	"ride-hail/internal/core/domain/types"
 )
 
 type Service interface {
-	Run()
+	Run() error
 	Stop(ctx context.Context) error
 }
 
@@ -55,10 +57,12 @@ func (app *App) Start(ctx context.Context) {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	log.Info(ctx, action.StartApplication, "starting service")
-	go app.svc.Run()
-
-	sig := <-sigChan
-	log.Warn(ctx, action.StopApplication, "received shutdown signal", "signal", sig.String())
+	if err := app.svc.Run(); err != nil {
+		log.Error(ctx, action.StartApplication, "failed to start service", "error", err)
+	} else {
+		sig := <-sigChan
+		log.Warn(ctx, action.StopApplication, "received shutdown signal", "signal", sig.String())
+	}
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -81,8 +85,10 @@ func initService(ctx context.Context, log *logger.Logger, cfg config.Config) (Se
 	switch cfg.Mode {
 	case types.ModeAdmin:
 		funcLog.Debug(ctx, action.StartApplication, "admin service mode detected")
+		return admin.New(ctx, log, cfg)
 	case types.ModeDAL:
 		funcLog.Debug(ctx, action.StartApplication, "driver location service mode detected")
+		return dal.New(ctx, log, cfg)
 	case types.ModeRide:
 		funcLog.Debug(ctx, action.StartApplication, "ride service mode detected")
 		return ride.New(ctx, log, cfg)
@@ -91,7 +97,4 @@ func initService(ctx context.Context, log *logger.Logger, cfg config.Config) (Se
 		funcLog.Error(ctx, action.StartApplication, "unsupported service mode", "mode", cfg.Mode, "error", err)
 		return nil, err
 	}
-
-	funcLog.Error(ctx, action.StopApplication, "service initialization failed", "mode", cfg.Mode)
-	return nil, nil
 }
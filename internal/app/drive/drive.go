@@ -2,18 +2,150 @@ package dal
 
 import (
 	"context"
+	"ride-hail/internal/adapters/http/handle"
+	"ride-hail/internal/adapters/http/server"
+	"ride-hail/internal/adapters/http/websocket"
+	"ride-hail/internal/adapters/osrm"
+	"ride-hail/internal/adapters/postgres"
+	rabbit2 "ride-hail/internal/adapters/rabbit"
+	"ride-hail/internal/core/ports"
+	"ride-hail/internal/core/service"
+	"ride-hail/internal/core/service/calculator"
+	"ride-hail/internal/core/service/routing"
+	"ride-hail/pkg/logger"
+	"ride-hail/pkg/rabbit"
+	"ride-hail/pkg/txm"
+	"time"
 
 	"ride-hail/config"
+	pg "ride-hail/pkg/potgres"
 )
 
-type DriverService struct{}
+// matchHandlerGrace — запас сверх dispatch.timeout_seconds на чтение поездки, поиск водителей и маршруты
+const matchHandlerGrace = 15 * time.Second
 
-func New(ctx context.Context, cfg config.Config) (*DriverService, error) {
-	return &DriverService{}, nil
+type DriverService struct {
+	server   server.Server
+	matching ports.MatchingService
+	dal      ports.DalService
+	relay    *service.OutboxRelay
+	cons     *rabbit2.Group
+	db       *pg.Postgres
+	wsm      *websocket.DriverWebSocketManager
+	rb       *rabbit.Rabbit
+	pub      *rabbit.Producer
+	log      *logger.Logger
+	cancel   context.CancelFunc
+	ctx      context.Context
 }
 
-func (r *DriverService) Run() error {
+func New(ctx context.Context, log *logger.Logger, cfg config.Config) (*DriverService, error) {
+	p, err := pg.New(ctx, cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	uRepo := postgres.NewRepo(p.Pool)
+	dRepo := postgres.NewDriverRepository(p.Pool)
+	cRepo := postgres.NewCordRepository(p.Pool)
+	rRepo := postgres.NewRideRepository(p.Pool)
+	stRepo := postgres.NewRideStopRepository(p.Pool)
+	lRepo := postgres.NewLocationRepository(p.Pool)
+	eRepo := postgres.NewRideEventRepository(p.Pool)
+	oRepo := postgres.NewOutboxRepository(p.Pool)
+	tRepo := postgres.NewTariffRepository(p.Pool)
+
+	rb, err := rabbit.New(cfg.RabbitMQ, log)
+	if err != nil {
+		p.Pool.Close()
+		return nil, err
+	}
+
+	if err = rb.SetTopology(rabbit2.InitRabbitTopology); err != nil {
+		rb.Close()
+		p.Pool.Close()
+		return nil, err
+	}
+
+	rPub := rabbit.NewPublisher(rb)
+	// запрос подтверждается после подбора, который длится до timeout_seconds, поэтому Workers — это
+	// число поездок, подбираемых одновременно, а обработчик ждёт весь подбор с запасом на базу и маршруты
+	rrCons := rabbit2.NewRideRequestConsumer(rb, rabbit.ConsumerOptions{
+		Prefetch:       cfg.Dispatch.MatchWorkers,
+		Workers:        cfg.Dispatch.MatchWorkers,
+		HandlerTimeout: time.Duration(cfg.Dispatch.TimeoutSeconds)*time.Second + matchHandlerGrace,
+	})
+	drCons := rabbit2.NewDriverReleaseConsumer(rb, rabbit.ConsumerOptions{})
+
+	tmx := txm.NewTXManager(p.Pool)
+
+	wsm := websocket.NewDriverWebSocketManager(ctx, log)
+	wsh := websocket.NewDriverWebSocketHandler(wsm, log)
+
+	var provider ports.RoutingProvider
+	if cfg.Routing.Provider == config.RoutingOSRM {
+		provider = osrm.NewClient(cfg.Routing.OSRMURL, time.Duration(cfg.Routing.TimeoutMs)*time.Millisecond)
+	}
+	router := routing.New(log, provider, cfg.Routing)
+
+	authServ := service.NewAuthService(cfg, uRepo, log)
+	relay := service.NewOutboxRelay(log, oRepo, rPub)
+	dalServ := service.NewDalService(log, tmx, dRepo, cRepo, rRepo, stRepo, lRepo, eRepo, oRepo, rPub, calculator.New(tRepo), wsm, drCons)
+	matchServ := service.NewMatchingService(log, tmx, dRepo, rRepo, oRepo, wsm, rrCons, router, cfg.Rating)
+	wsm.SetServices(matchServ, dalServ)
+
+	authHandle := handle.New(cfg, authServ, log)
+	dalHandle := handle.NewDalHandler(dalServ, wsh, log)
+
+	serv, err := server.New(cfg, log, authHandle, nil, dalHandle, nil)
+	if err != nil {
+		rb.Close()
+		p.Pool.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &DriverService{
+		server:   serv,
+		matching: matchServ,
+		dal:      dalServ,
+		relay:    relay,
+		cons:     rabbit2.NewGroup(rrCons, drCons),
+		wsm:      wsm,
+		db:       p,
+		rb:       rb,
+		pub:      rPub,
+		log:      log,
+		ctx:      ctx,
+		cancel:   cancel,
+	}, nil
+}
+
+func (d *DriverService) Run() error {
+	if err := d.cons.Start(d.ctx); err != nil {
+		return err
+	}
+
+	go d.relay.Run(d.ctx)
+	go d.matching.StartService(d.ctx)
+	go d.dal.StartService(d.ctx)
+	go d.server.Run()
 	return nil
 }
 
-func (r *DriverService) Stop() {}
+func (d *DriverService) Stop(ctx context.Context) error {
+	d.cons.Stop()
+	d.cancel()
+
+	d.wsm.Shutdown()
+
+	if err := d.server.Stop(ctx); err != nil {
+		return err
+	}
+
+	d.pub.Close()
+	d.rb.Close()
+	d.db.Pool.Close()
+	return nil
+}
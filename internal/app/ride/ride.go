@@ -5,13 +5,18 @@ import (
 	"ride-hail/internal/adapters/http/handle"
 	"ride-hail/internal/adapters/http/server"
 	"ride-hail/internal/adapters/http/websocket"
+	"ride-hail/internal/adapters/osrm"
 	"ride-hail/internal/adapters/postgres"
 	rabbit2 "ride-hail/internal/adapters/rabbit"
 	"ride-hail/internal/core/ports"
 	"ride-hail/internal/core/service"
+	"ride-hail/internal/core/service/calculator"
+	"ride-hail/internal/core/service/routing"
+	"ride-hail/internal/core/service/surge"
 	"ride-hail/pkg/logger"
 	"ride-hail/pkg/rabbit"
 	"ride-hail/pkg/txm"
+	"time"
 
 	"ride-hail/config"
 	pg "ride-hail/pkg/potgres"
@@ -20,6 +25,11 @@ import (
 type RideService struct {
 	server server.Server
 	svc    ports.RideService
+	relay  *service.OutboxRelay
+	cons   *rabbit2.Group
+	pub    *rabbit.Producer
+	rb     *rabbit.Rabbit
+	db     *pg.Postgres
 	wsm    *websocket.PassengerWebSocketManager
 	cancel context.CancelFunc
 	ctx    context.Context
@@ -33,35 +43,51 @@ func New(ctx context.Context, log *logger.Logger, cfg config.Config) (*RideServi
 
 	uRepo := postgres.NewRepo(p.Pool)
 	cRepo := postgres.NewCordRepository(p.Pool)
+	stRepo := postgres.NewRideStopRepository(p.Pool)
+	raRepo := postgres.NewRatingRepository(p.Pool)
 	rRepo := postgres.NewRideRepository(p.Pool)
+	eRepo := postgres.NewRideEventRepository(p.Pool)
+	oRepo := postgres.NewOutboxRepository(p.Pool)
+	tRepo := postgres.NewTariffRepository(p.Pool)
+	sRepo := postgres.NewSurgeRepository(p.Pool)
 
-	rb, err := rabbit.New(cfg.RabbitMQ)
+	rb, err := rabbit.New(cfg.RabbitMQ, log)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = rabbit2.InitRabbitTopology(rb); err != nil {
+	if err = rb.SetTopology(rabbit2.InitRabbitTopology); err != nil {
 		return nil, err
 	}
 
-	rPub := rabbit.NewPublisher(rb.Conn)
-	lCons := rabbit2.NewLocationConsumer(rb.Conn)
-	dmCons := rabbit2.NewDriverResponseConsumer(rb.Conn)
-	rSCons := rabbit2.NewRideStatusConsumer(rb.Conn)
+	rPub := rabbit.NewPublisher(rb)
+	lCons := rabbit2.NewLocationConsumer(rb, rabbit.ConsumerOptions{Prefetch: 50, Workers: 8})
+	dmCons := rabbit2.NewDriverResponseConsumer(rb, rabbit.ConsumerOptions{})
+	// статусы одной поездки применяются по порядку, поэтому один воркер
+	rSCons := rabbit2.NewRideStatusConsumer(rb, rabbit.ConsumerOptions{Workers: 1})
 
 	tmx := txm.NewTXManager(p.Pool)
 
 	wsm := websocket.NewPassengerWebSocketManager(ctx, log)
 	wsh := websocket.NewPassengerWebSocketHandler(wsm, log)
 
+	var provider ports.RoutingProvider
+	if cfg.Routing.Provider == config.RoutingOSRM {
+		provider = osrm.NewClient(cfg.Routing.OSRMURL, time.Duration(cfg.Routing.TimeoutMs)*time.Millisecond)
+	}
+	router := routing.New(log, provider, cfg.Routing)
+
 	authServ := service.NewAuthService(cfg, uRepo, log)
-	rideServ := service.NewRideService(log, tmx, rRepo, cRepo, wsm, rPub, lCons, dmCons, rSCons)
+	relay := service.NewOutboxRelay(log, oRepo, rPub)
+	rideServ := service.NewRideService(log, cfg, tmx, calculator.New(tRepo), surge.New(sRepo, cfg.Surge), router, rRepo, cRepo, stRepo, raRepo, eRepo, oRepo, wsm, lCons, dmCons, rSCons)
 
 	authHandle := handle.New(cfg, authServ, log)
 	rideHandle := handle.NewRideHandle(rideServ, wsh, log)
 
-	serv, err := server.New(cfg, log, authHandle, rideHandle)
+	serv, err := server.New(cfg, log, authHandle, rideHandle, nil, nil)
 	if err != nil {
+		rb.Close()
+		p.Pool.Close()
 		return nil, err
 	}
 
@@ -70,18 +96,31 @@ func New(ctx context.Context, log *logger.Logger, cfg config.Config) (*RideServi
 	return &RideService{
 		server: serv,
 		svc:    rideServ,
+		relay:  relay,
+		cons:   rabbit2.NewGroup(lCons, dmCons, rSCons),
+		pub:    rPub,
+		rb:     rb,
+		db:     p,
 		wsm:    wsm,
 		ctx:    ctx,
 		cancel: cancel,
 	}, nil
 }
 
-func (r *RideService) Run() {
+func (r *RideService) Run() error {
+	if err := r.cons.Start(r.ctx); err != nil {
+		return err
+	}
+
+	go r.relay.Run(r.ctx)
 	go r.svc.StartService(r.ctx)
 	go r.server.Run()
+	return nil
 }
 
 func (r *RideService) Stop(ctx context.Context) error {
+	// консьюмеры дорабатывают полученные сообщения, пока сервис и WebSocket ещё живы
+	r.cons.Stop()
 	r.cancel()
 
 	r.wsm.Shutdown()
@@ -89,5 +128,9 @@ func (r *RideService) Stop(ctx context.Context) error {
 	if err := r.server.Stop(ctx); err != nil {
 		return err
 	}
+
+	r.pub.Close()
+	r.rb.Close()
+	r.db.Pool.Close()
 	return nil
 }
@@ -12,15 +12,36 @@ var (
 )
 
 var (
-	ServiceRide = "parsing data in message broker"
-	CreateRide  = "create ride"
-	CloseRide   = "close ride"
+	ServiceRide  = "parsing data in message broker"
+	CreateRide   = "create ride"
+	EstimateRide = "estimate ride"
+	CloseRide    = "close ride"
+	DispatchRide = "dispatch ride"
+	ScheduleRide = "schedule ride"
+	RideHistory  = "ride history"
+	RateRide     = "rate ride"
 )
 
 var (
 	WSPassenger = "ws passenger"
+	WSDriver    = "ws driver"
 )
 
 var (
-	UpdateStatus = "updating status"
+	UpdateStatus  = "updating status"
+	MatchRide     = "match ride"
+	Location      = "update location"
+	RideProgress  = "ride progress"
+	RideEvents    = "ride events"
+	DriverHistory = "driver history"
+	Outbox        = "outbox"
+	Routing       = "routing"
+)
+
+var (
+	AdminOverview    = "admin overview"
+	AdminActiveRides = "admin active rides"
+	AdminDeadLetters = "admin dead letters"
+	AdminOutbox      = "admin outbox"
+	AdminTariffs     = "admin tariffs"
 )
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+type OverviewMetrics struct {
+	Timestamp          time.Time      `json:"timestamp"`
+	Metrics            Metrics        `json:"metrics"`
+	DriverDistribution map[string]int `json:"driver_distribution"`
+}
+
+type Metrics struct {
+	ActiveRides                int     `json:"active_rides"`
+	OnlineDrivers              int     `json:"online_drivers"`
+	AvailableDrivers           int     `json:"available_drivers"`
+	BusyDrivers                int     `json:"busy_drivers"`
+	TotalRidesToday            int     `json:"total_rides_today"`
+	TotalRevenueToday          float64 `json:"total_revenue_today"`
+	AverageWaitTimeMinutes     float64 `json:"average_wait_time_minutes"`
+	AverageRideDurationMinutes float64 `json:"average_ride_duration_minutes"`
+	CancellationRate           float64 `json:"cancellation_rate"`
+}
+
+type ActiveRide struct {
+	RideID             string     `json:"ride_id"`
+	RideNumber         string     `json:"ride_number"`
+	Status             string     `json:"status"`
+	PassengerID        string     `json:"passenger_id"`
+	DriverID           string     `json:"driver_id,omitempty"`
+	VehicleType        string     `json:"vehicle_type"`
+	PickupAddress      string     `json:"pickup_address"`
+	DestinationAddress string     `json:"destination_address"`
+	EstimatedFare      float64    `json:"estimated_fare"`
+	RequestedAt        time.Time  `json:"requested_at"`
+	StartedAt          *time.Time `json:"started_at,omitempty"`
+}
+
+type ActiveRidesPage struct {
+	Rides      []ActiveRide `json:"rides"`
+	TotalCount int          `json:"total_count"`
+	Page       int          `json:"page"`
+	PageSize   int          `json:"page_size"`
+}
+
+type DeadLetter struct {
+	MessageID     string    `json:"message_id"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	Queue         string    `json:"queue"`
+	RoutingKey    string    `json:"routing_key"`
+	Reason        string    `json:"reason"`
+	RetryCount    int       `json:"retry_count"`
+	Timestamp     time.Time `json:"timestamp"`
+	Body          string    `json:"body"`
+}
+
+type ReplayResponse struct {
+	Queue    string `json:"queue"`
+	Replayed int    `json:"replayed"`
+}
@@ -21,3 +21,9 @@ type Coordinate struct {
 	DurationMinutes int       `json:"duration_minutes"`
 	IsCurrent       bool      `json:"is_current"`
 }
+
+// Route — расстояние и время в пути по дорогам между двумя точками
+type Route struct {
+	DistanceKm      float64 `json:"distance_km"`
+	DurationMinutes int     `json:"duration_minutes"`
+}
@@ -18,6 +18,16 @@ type LocationDriver struct {
 	Lng float64 `json:"lng"`
 }
 
+// DriverReleaseEvent — поездка больше не достаётся водителю: пассажир её отменил
+// или она ушла другому, пока водитель принимал предложение
+type DriverReleaseEvent struct {
+	RideID        string    `json:"ride_id"`
+	DriverID      string    `json:"driver_id"`
+	Reason        string    `json:"reason,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+}
+
 type DriverResponseEvent struct {
 	RideID                  string `json:"ride_id"`
 	DriverID                string `json:"driver_id"`
@@ -86,3 +96,76 @@ type DriverInfoClosed struct {
 	SessionSummary SessionSummary `json:"session_summary"`
 	Message        string         `json:"message"`
 }
+
+type DriverSearch struct {
+	Latitude    float64
+	Longitude   float64
+	VehicleType string
+	RadiusKm    float64
+	Limit       int
+}
+
+type DriverCandidate struct {
+	DriverID                string       `json:"driver_id"`
+	Name                    string       `json:"name"`
+	Rating                  float64      `json:"rating"`
+	VehicleAttrs            VehicleAttrs `json:"vehicle_attrs"`
+	Location                Location     `json:"location"`
+	DistanceKm              float64      `json:"distance_km"`
+	EstimatedArrivalMinutes int          `json:"estimated_arrival_minutes"`
+}
+
+type RideOffer struct {
+	OfferID                      string     `json:"offer_id"`
+	RideID                       string     `json:"ride_id"`
+	RideNumber                   string     `json:"ride_number"`
+	PickupLocation               Location   `json:"pickup_location"`
+	DestinationLocation          Location   `json:"destination_location"`
+	Stops                        []Location `json:"stops,omitempty"`
+	EstimatedFare                float64    `json:"estimated_fare"`
+	DistanceToPickupKm           float64    `json:"distance_to_pickup_km"`
+	EstimatedRideDurationMinutes int        `json:"estimated_ride_duration_minutes"`
+	ExpiresAt                    time.Time  `json:"expires_at"`
+}
+
+type LocationUpdate struct {
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	AccuracyMeters float64 `json:"accuracy_meters"`
+	SpeedKmh       float64 `json:"speed_kmh"`
+	HeadingDegrees float64 `json:"heading_degrees"`
+}
+
+type LocationHistory struct {
+	ID             string    `json:"id"`
+	CoordinateID   string    `json:"coordinate_id"`
+	DriverID       string    `json:"driver_id"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	AccuracyMeters float64   `json:"accuracy_meters"`
+	SpeedKmh       float64   `json:"speed_kmh"`
+	HeadingDegrees float64   `json:"heading_degrees"`
+	RecordedAt     time.Time `json:"recorded_at"`
+	RideID         string    `json:"ride_id"`
+}
+
+// SessionEarnings — смена водителя, пересекающаяся с периодом отчёта; EndedAt пуст у открытой смены
+type SessionEarnings struct {
+	SessionID      string     `json:"session_id"`
+	StartedAt      time.Time  `json:"started_at"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+	DurationHours  float64    `json:"duration_hours"`
+	RidesCompleted int        `json:"rides_completed"`
+	Earnings       float64    `json:"earnings"`
+}
+
+// DriverEarnings — заработок за период [From, To) по завершённым поездкам и разбивка по сменам
+type DriverEarnings struct {
+	DriverID       string            `json:"driver_id"`
+	Period         string            `json:"period"`
+	From           time.Time         `json:"from"`
+	To             time.Time         `json:"to"`
+	RidesCompleted int               `json:"rides_completed"`
+	TotalEarnings  float64           `json:"total_earnings"`
+	Sessions       []SessionEarnings `json:"sessions"`
+}
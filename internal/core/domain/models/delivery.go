@@ -0,0 +1,35 @@
+package models
+
+import "context"
+
+// Delivery — событие из брокера вместе с обратной связью для адаптера.
+// Сообщение подтверждается только после Done(nil), при ошибке брокер повторит доставку.
+type Delivery[T any] struct {
+	Event T
+	done  chan error
+}
+
+func NewDelivery[T any](event T) Delivery[T] {
+	return Delivery[T]{
+		Event: event,
+		done:  make(chan error, 1),
+	}
+}
+
+// Done сообщает результат обработки, повторные вызовы игнорируются
+func (d Delivery[T]) Done(err error) {
+	select {
+	case d.done <- err:
+	default:
+	}
+}
+
+// Wait ждёт результата обработки или отмены контекста
+func (d Delivery[T]) Wait(ctx context.Context) error {
+	select {
+	case err := <-d.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -8,3 +8,17 @@ type Claims struct {
 	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
+
+// EstimateClaims — подписанная оценка поездки, по ней CreateNewRide фиксирует цену
+type EstimateClaims struct {
+	PassengerID          string          `json:"passenger_id"`
+	PickupLatitude       float64         `json:"pickup_latitude"`
+	PickupLongitude      float64         `json:"pickup_longitude"`
+	DestinationLatitude  float64         `json:"destination_latitude"`
+	DestinationLongitude float64         `json:"destination_longitude"`
+	Stops                []Position      `json:"stops,omitempty"`
+	DistanceKm           float64         `json:"distance_km"`
+	DurationMinutes      int             `json:"duration_minutes"`
+	Fares                map[string]Fare `json:"fares"`
+	jwt.RegisteredClaims
+}
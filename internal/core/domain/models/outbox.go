@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type OutboxMessage struct {
+	ID            string
+	CreatedAt     time.Time
+	Exchange      string
+	RoutingKey    string
+	Payload       []byte
+	CorrelationID string
+	Attempts      int
+	Rejections    int // сколько раз брокер ответил nack
+}
+
+// ParkedMessage — сообщение outbox, снятое с отправки, с причиной последней неудачи
+type ParkedMessage struct {
+	ID            string    `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	ParkedAt      time.Time `json:"parked_at"`
+	Exchange      string    `json:"exchange"`
+	RoutingKey    string    `json:"routing_key"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error"`
+	Payload       string    `json:"payload"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// RideRating — оценка одной стороны поездки другой; RaterRole — passenger или driver
+type RideRating struct {
+	RideID    string
+	RaterID   string
+	RateeID   string
+	RaterRole string
+	Score     int
+	Comment   string
+	Tags      []string
+}
+
+type RateRideRequest struct {
+	RideID  string   `json:"-"`
+	Score   int      `json:"score"`
+	Comment string   `json:"comment,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+type RateRideResponse struct {
+	RideID      string    `json:"ride_id"`
+	RateeID     string    `json:"ratee_id"`
+	Score       int       `json:"score"`
+	RateeRating float64   `json:"ratee_rating"`
+	RatedAt     time.Time `json:"rated_at"`
+}
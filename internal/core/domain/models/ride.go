@@ -11,6 +11,37 @@ type CreateRideRequest struct {
 	DestinationLongitude float64 `json:"destination_longitude"`
 	DestinationAddress   string  `json:"destination_address"`
 	RideType             string  `json:"ride_type"`
+	EstimateToken        string  `json:"estimate_token,omitempty"`
+	// AcceptedSurgeMultiplier — коэффициент, с которым пассажир согласился; нужен выше порога подтверждения
+	AcceptedSurgeMultiplier float64 `json:"accepted_surge_multiplier,omitempty"`
+	// ScheduledAt — время подачи для заказа заранее; без него водитель ищется сразу
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	// Stops — промежуточные остановки между подачей и назначением в порядке объезда
+	Stops []Location `json:"stops,omitempty"`
+}
+
+type EstimateRideRequest struct {
+	PickupLatitude       float64    `json:"pickup_latitude"`
+	PickupLongitude      float64    `json:"pickup_longitude"`
+	DestinationLatitude  float64    `json:"destination_latitude"`
+	DestinationLongitude float64    `json:"destination_longitude"`
+	Stops                []Location `json:"stops,omitempty"`
+}
+
+type RideEstimate struct {
+	RideType      string  `json:"ride_type"`
+	EstimatedFare float64 `json:"estimated_fare"`
+	Currency      string  `json:"currency"`
+}
+
+type EstimateRideResponse struct {
+	EstimatedDistanceKm      float64        `json:"estimated_distance_km"`
+	EstimatedDurationMinutes int            `json:"estimated_duration_minutes"`
+	SurgeMultiplier          float64        `json:"surge_multiplier"`
+	SurgeAckRequired         bool           `json:"surge_ack_required"`
+	Estimates                []RideEstimate `json:"estimates"`
+	EstimateToken            string         `json:"estimate_token"`
+	ExpiresAt                time.Time      `json:"expires_at"`
 }
 
 type Ride struct {
@@ -32,17 +63,31 @@ type Ride struct {
 	CancellationReason      string    `json:"cancellation_reason"`
 	EstimatedFare           float64   `json:"estimated_fare"`
 	FinalFare               float64   `json:"final_fare"`
+	TariffID                string    `json:"tariff_id"`
+	SurgeMultiplier         float64   `json:"surge_multiplier"`
+	ScheduledAt             time.Time `json:"scheduled_at"`
 	PickupCoordinateId      string    `json:"pickup_coordinate_id"`
 	DestinationCoordinateId string    `json:"destination_coordinate_id"`
 }
 
 type CreateRideResponse struct {
-	RideID                   string  `json:"ride_id"`
-	RideNumber               string  `json:"ride_number"`
-	Status                   string  `json:"status"`
-	EstimatedFare            float64 `json:"estimated_fare"`
-	EstimatedDurationMinutes int     `json:"estimated_duration_minutes"`
-	EstimatedDistanceKm      float64 `json:"estimated_distance_km"`
+	RideID                   string     `json:"ride_id"`
+	RideNumber               string     `json:"ride_number"`
+	Status                   string     `json:"status"`
+	EstimatedFare            float64    `json:"estimated_fare"`
+	Currency                 string     `json:"currency"`
+	SurgeMultiplier          float64    `json:"surge_multiplier"`
+	EstimatedDurationMinutes int        `json:"estimated_duration_minutes"`
+	EstimatedDistanceKm      float64    `json:"estimated_distance_km"`
+	ScheduledAt              *time.Time `json:"scheduled_at,omitempty"`
+}
+
+// UpdateRideRequest — изменение запланированной поездки; пустые поля остаются как были
+type UpdateRideRequest struct {
+	RideID              string     `json:"-"`
+	ScheduledAt         *time.Time `json:"scheduled_at,omitempty"`
+	PickupLocation      *Location  `json:"pickup_location,omitempty"`
+	DestinationLocation *Location  `json:"destination_location,omitempty"`
 }
 
 type CloseRideRequest struct {
@@ -58,15 +103,24 @@ type CloseRideResponse struct {
 }
 
 type RideRequestRideType struct {
-	RideID              string   `json:"ride_id"`
-	RideNumber          string   `json:"ride_number"`
-	PickupLocation      Location `json:"pickup_location"`
-	DestinationLocation Location `json:"destination_location"`
-	RideType            string   `json:"ride_type"`
-	EstimatedFare       float64  `json:"estimated_fare"`
-	MaxDistanceKm       float64  `json:"max_distance_km"`
-	TimeoutSeconds      int      `json:"timeout_seconds"`
-	CorrelationID       string   `json:"correlation_id"`
+	RideID              string     `json:"ride_id"`
+	RideNumber          string     `json:"ride_number"`
+	PickupLocation      Location   `json:"pickup_location"`
+	DestinationLocation Location   `json:"destination_location"`
+	RideType            string     `json:"ride_type"`
+	EstimatedFare       float64    `json:"estimated_fare"`
+	Stops               []Location `json:"stops,omitempty"`
+	MaxDistanceKm       float64    `json:"max_distance_km"`
+	TimeoutSeconds      int        `json:"timeout_seconds"`
+	CorrelationID       string     `json:"correlation_id"`
+}
+
+// RideStop — промежуточная остановка поездки; ReachedAt пуст, пока водитель до неё не доехал
+type RideStop struct {
+	Seq          int        `json:"seq"`
+	CoordinateID string     `json:"coordinate_id"`
+	Location     Location   `json:"location"`
+	ReachedAt    *time.Time `json:"reached_at,omitempty"`
 }
 
 type Location struct {
@@ -76,17 +130,110 @@ type Location struct {
 }
 
 type RideStatusUpdate struct {
-	RideID        string    `json:"ride_id"`
-	Status        string    `json:"status"`
-	Timestamp     time.Time `json:"timestamp"`
-	DriverID      string    `json:"driver_id"`
-	CorrelationID string    `json:"correlation_id"`
+	RideID        string     `json:"ride_id"`
+	Status        string     `json:"status"`
+	Timestamp     time.Time  `json:"timestamp"`
+	DriverID      string     `json:"driver_id"`
+	Reason        string     `json:"reason,omitempty"`
+	ScheduledAt   *time.Time `json:"scheduled_at,omitempty"`
+	CorrelationID string     `json:"correlation_id"`
+}
+
+// RideDispatch — поездка, для которой истекло ожидание водителя
+type RideDispatch struct {
+	Ride     Ride
+	Attempts int
 }
 
 type RideStatusEvent struct {
-	RideID        string    `json:"ride_id"`
-	Status        string    `json:"status"`
-	Timestamp     time.Time `json:"timestamp"`
-	DriverID      string    `json:"driver_id"`
-	CorrelationID string    `json:"correlation_id"`
+	RideID        string     `json:"ride_id"`
+	Status        string     `json:"status"`
+	Timestamp     time.Time  `json:"timestamp"`
+	DriverID      string     `json:"driver_id"`
+	Reason        string     `json:"reason,omitempty"`
+	ScheduledAt   *time.Time `json:"scheduled_at,omitempty"`
+	CorrelationID string     `json:"correlation_id"`
+}
+
+type RideProgressResponse struct {
+	RideID    string    `json:"ride_id"`
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	FinalFare float64   `json:"final_fare,omitempty"`
+	Message   string    `json:"message"`
+}
+
+// RideFilter — выборка поездок пассажира или водителя; Cursor — непрозрачная позиция после последней поездки предыдущей страницы
+type RideFilter struct {
+	PassengerID string
+	DriverID    string
+	Statuses    []string
+	From        *time.Time
+	To          *time.Time
+	Cursor      string
+	Limit       int
+}
+
+// RideCursor — позиция в истории, отсортированной по requested_at и id по убыванию
+type RideCursor struct {
+	RequestedAt time.Time
+	RideID      string
+}
+
+type RideSummary struct {
+	RideID             string     `json:"ride_id"`
+	RideNumber         string     `json:"ride_number"`
+	Status             string     `json:"status"`
+	VehicleType        string     `json:"vehicle_type"`
+	PickupAddress      string     `json:"pickup_address"`
+	DestinationAddress string     `json:"destination_address"`
+	EstimatedFare      float64    `json:"estimated_fare"`
+	FinalFare          *float64   `json:"final_fare,omitempty"`
+	Currency           string     `json:"currency"`
+	RequestedAt        time.Time  `json:"requested_at"`
+	ScheduledAt        *time.Time `json:"scheduled_at,omitempty"`
+	CompletedAt        *time.Time `json:"completed_at,omitempty"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+}
+
+type RidesPage struct {
+	Rides      []RideSummary `json:"rides"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// RideDriver — водитель поездки в том виде, в каком его видит пассажир
+type RideDriver struct {
+	DriverID string  `json:"driver_id"`
+	Name     string  `json:"name"`
+	Rating   float64 `json:"rating"`
+	Vehicle  struct {
+		Make  string `json:"make"`
+		Model string `json:"model"`
+		Color string `json:"color"`
+		Plate string `json:"plate"`
+	} `json:"vehicle"`
+}
+
+type RideDetail struct {
+	RideID              string      `json:"ride_id"`
+	RideNumber          string      `json:"ride_number"`
+	PassengerID         string      `json:"passenger_id"`
+	Status              string      `json:"status"`
+	VehicleType         string      `json:"vehicle_type"`
+	PickupLocation      Location    `json:"pickup_location"`
+	DestinationLocation Location    `json:"destination_location"`
+	Stops               []RideStop  `json:"stops,omitempty"`
+	Driver              *RideDriver `json:"driver,omitempty"`
+	EstimatedFare       float64     `json:"estimated_fare"`
+	FinalFare           *float64    `json:"final_fare,omitempty"`
+	Currency            string      `json:"currency"`
+	SurgeMultiplier     float64     `json:"surge_multiplier"`
+	RequestedAt         time.Time   `json:"requested_at"`
+	ScheduledAt         *time.Time  `json:"scheduled_at,omitempty"`
+	MatchedAt           *time.Time  `json:"matched_at,omitempty"`
+	ArrivedAt           *time.Time  `json:"arrived_at,omitempty"`
+	StartedAt           *time.Time  `json:"started_at,omitempty"`
+	CompletedAt         *time.Time  `json:"completed_at,omitempty"`
+	CancelledAt         *time.Time  `json:"cancelled_at,omitempty"`
+	CancellationReason  string      `json:"cancellation_reason,omitempty"`
 }
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type RideEvent struct {
+	ID        string          `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	RideID    string          `json:"ride_id"`
+	EventType string          `json:"event_type"`
+	EventData json.RawMessage `json:"event_data"`
+}
+
+// RideEventData — содержимое event_data, заполняются только относящиеся к событию поля
+type RideEventData struct {
+	OldStatus     string          `json:"old_status,omitempty"`
+	NewStatus     string          `json:"new_status,omitempty"`
+	PassengerID   string          `json:"passenger_id,omitempty"`
+	DriverID      string          `json:"driver_id,omitempty"`
+	Location      *LocationDriver `json:"location,omitempty"`
+	SpeedKmh      float64         `json:"speed_kmh,omitempty"`
+	Heading       float64         `json:"heading_degrees,omitempty"`
+	EstimatedFare float64         `json:"estimated_fare,omitempty"`
+	FinalFare     float64         `json:"final_fare,omitempty"`
+	Reason        string          `json:"reason,omitempty"`
+	RadiusKm      float64         `json:"search_radius_km,omitempty"`
+	ScheduledAt   *time.Time      `json:"scheduled_at,omitempty"`
+	StopSeq       int             `json:"stop_seq,omitempty"`
+	Score         int             `json:"score,omitempty"`
+	Timestamp     time.Time       `json:"timestamp"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+type Tariff struct {
+	ID          string    `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	VehicleType string    `json:"vehicle_type"`
+	BaseFare    float64   `json:"base_fare"`
+	RatePerKm   float64   `json:"rate_per_km"`
+	RatePerMin  float64   `json:"rate_per_min"`
+	MinimumFare float64   `json:"minimum_fare"`
+	BookingFee  float64   `json:"booking_fee"`
+	// FreeWaitingMinutes после прибытия водителя бесплатны, дальше ожидание по WaitingRatePerMin
+	FreeWaitingMinutes int       `json:"free_waiting_minutes"`
+	WaitingRatePerMin  float64   `json:"waiting_rate_per_min"`
+	Currency           string    `json:"currency"`
+	EffectiveFrom      time.Time `json:"effective_from"`
+}
+
+// Trip — из чего складывается стоимость поездки
+type Trip struct {
+	DistanceKm      float64
+	DurationMinutes int
+	WaitingMinutes  int
+}
+
+// Fare — посчитанная стоимость и версия тарифа, по которой она получена
+type Fare struct {
+	Amount          float64 `json:"amount"`
+	Currency        string  `json:"currency"`
+	TariffID        string  `json:"tariff_id"`
+	SurgeMultiplier float64 `json:"surge_multiplier"`
+}
+
+type CreateTariffRequest struct {
+	VehicleType        string     `json:"vehicle_type"`
+	BaseFare           float64    `json:"base_fare"`
+	RatePerKm          float64    `json:"rate_per_km"`
+	RatePerMin         float64    `json:"rate_per_min"`
+	MinimumFare        float64    `json:"minimum_fare"`
+	BookingFee         float64    `json:"booking_fee"`
+	FreeWaitingMinutes int        `json:"free_waiting_minutes"`
+	WaitingRatePerMin  float64    `json:"waiting_rate_per_min"`
+	Currency           string     `json:"currency"`
+	EffectiveFrom      *time.Time `json:"effective_from,omitempty"`
+}
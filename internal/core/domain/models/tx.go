@@ -0,0 +1,8 @@
+package models
+
+type txKey struct{}
+
+// GetTxKey returns the context key under which the active pgx.Tx is stored
+func GetTxKey() any {
+	return txKey{}
+}
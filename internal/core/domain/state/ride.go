@@ -0,0 +1,59 @@
+package state
+
+import (
+	"fmt"
+	"ride-hail/internal/core/domain/types"
+	"slices"
+)
+
+// Инициаторы переходов между статусами поездки
+const (
+	ActorPassenger = "passenger"
+	ActorDriver    = "driver"
+	ActorSystem    = "system"
+)
+
+type transition struct {
+	from string
+	to   string
+}
+
+// rideTransitions — допустимые переходы и кто может их выполнить
+var rideTransitions = map[transition][]string{
+	{types.RideStatusSCHEDULED, types.RideStatusREQUESTED}: {ActorSystem},
+	{types.RideStatusSCHEDULED, types.RideStatusCANCELLED}: {ActorPassenger, ActorSystem},
+
+	{types.RideStatusREQUESTED, types.RideStatusMATCHED}:   {ActorSystem},
+	{types.RideStatusREQUESTED, types.RideStatusCANCELLED}: {ActorPassenger, ActorSystem},
+
+	{types.RideStatusMATCHED, types.RideStatusEN_ROUTE}:  {ActorDriver, ActorSystem},
+	{types.RideStatusMATCHED, types.RideStatusARRIVED}:   {ActorDriver},
+	{types.RideStatusMATCHED, types.RideStatusCANCELLED}: {ActorPassenger, ActorDriver, ActorSystem},
+
+	{types.RideStatusEN_ROUTE, types.RideStatusARRIVED}:   {ActorDriver},
+	{types.RideStatusEN_ROUTE, types.RideStatusCANCELLED}: {ActorPassenger, ActorDriver, ActorSystem},
+
+	{types.RideStatusARRIVED, types.RideStatusIN_PROGRESS}: {ActorDriver},
+	{types.RideStatusARRIVED, types.RideStatusCANCELLED}:   {ActorPassenger, ActorDriver, ActorSystem},
+
+	{types.RideStatusIN_PROGRESS, types.RideStatusCOMPLETED}: {ActorDriver},
+}
+
+// CanTransition проверяет, может ли actor перевести поездку из from в to
+func CanTransition(from, to, actor string) error {
+	actors, ok := rideTransitions[transition{from: from, to: to}]
+	if !ok {
+		return fmt.Errorf("%w: %s -> %s", types.ErrInvalidTransition, from, to)
+	}
+
+	if !slices.Contains(actors, actor) {
+		return fmt.Errorf("%w: %s cannot move ride %s -> %s", types.ErrTransitionForbidden, actor, from, to)
+	}
+
+	return nil
+}
+
+// IsFinal сообщает, что из статуса больше нет переходов
+func IsFinal(status string) bool {
+	return status == types.RideStatusCOMPLETED || status == types.RideStatusCANCELLED
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"ride-hail/internal/core/domain/types"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to, actor string
+		wantErr         error
+	}{
+		{types.RideStatusSCHEDULED, types.RideStatusREQUESTED, ActorSystem, nil},
+		{types.RideStatusSCHEDULED, types.RideStatusREQUESTED, ActorPassenger, types.ErrTransitionForbidden},
+		{types.RideStatusSCHEDULED, types.RideStatusCANCELLED, ActorPassenger, nil},
+		{types.RideStatusSCHEDULED, types.RideStatusMATCHED, ActorSystem, types.ErrInvalidTransition},
+
+		{types.RideStatusREQUESTED, types.RideStatusMATCHED, ActorSystem, nil},
+		{types.RideStatusREQUESTED, types.RideStatusMATCHED, ActorDriver, types.ErrTransitionForbidden},
+		{types.RideStatusREQUESTED, types.RideStatusCANCELLED, ActorPassenger, nil},
+		{types.RideStatusREQUESTED, types.RideStatusCANCELLED, ActorDriver, types.ErrTransitionForbidden},
+		{types.RideStatusREQUESTED, types.RideStatusIN_PROGRESS, ActorDriver, types.ErrInvalidTransition},
+
+		{types.RideStatusMATCHED, types.RideStatusEN_ROUTE, ActorDriver, nil},
+		{types.RideStatusMATCHED, types.RideStatusARRIVED, ActorDriver, nil},
+		{types.RideStatusMATCHED, types.RideStatusARRIVED, ActorSystem, types.ErrTransitionForbidden},
+		{types.RideStatusMATCHED, types.RideStatusCANCELLED, ActorDriver, nil},
+		{types.RideStatusMATCHED, types.RideStatusCOMPLETED, ActorDriver, types.ErrInvalidTransition},
+
+		{types.RideStatusEN_ROUTE, types.RideStatusARRIVED, ActorDriver, nil},
+		{types.RideStatusEN_ROUTE, types.RideStatusCANCELLED, ActorPassenger, nil},
+
+		{types.RideStatusARRIVED, types.RideStatusIN_PROGRESS, ActorDriver, nil},
+		{types.RideStatusARRIVED, types.RideStatusIN_PROGRESS, ActorPassenger, types.ErrTransitionForbidden},
+		{types.RideStatusARRIVED, types.RideStatusCANCELLED, ActorSystem, nil},
+
+		{types.RideStatusIN_PROGRESS, types.RideStatusCOMPLETED, ActorDriver, nil},
+		{types.RideStatusIN_PROGRESS, types.RideStatusCOMPLETED, ActorSystem, types.ErrTransitionForbidden},
+		{types.RideStatusIN_PROGRESS, types.RideStatusCANCELLED, ActorPassenger, types.ErrInvalidTransition},
+
+		{types.RideStatusCOMPLETED, types.RideStatusCANCELLED, ActorSystem, types.ErrInvalidTransition},
+		{types.RideStatusCANCELLED, types.RideStatusREQUESTED, ActorSystem, types.ErrInvalidTransition},
+		{types.RideStatusMATCHED, types.RideStatusMATCHED, ActorSystem, types.ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to+"/"+tt.actor, func(t *testing.T) {
+			err := CanTransition(tt.from, tt.to, tt.actor)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("CanTransition() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CanTransition() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFinalStatusesHaveNoTransitions(t *testing.T) {
+	for _, from := range types.RideStatuses {
+		for _, to := range types.RideStatuses {
+			for _, actor := range []string{ActorPassenger, ActorDriver, ActorSystem} {
+				if IsFinal(from) && CanTransition(from, to, actor) == nil {
+					t.Errorf("final status %s allows %s -> %s by %s", from, from, to, actor)
+				}
+			}
+		}
+	}
+
+	for _, status := range types.RideStatuses {
+		want := status == types.RideStatusCOMPLETED || status == types.RideStatusCANCELLED
+		if got := IsFinal(status); got != want {
+			t.Errorf("IsFinal(%s) = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -8,7 +8,31 @@ var (
 	ErrUserAlreadyExists = errors.New("user already exists")
 )
 
-var ErrRideNotFound = errors.New("ride not found")
+var (
+	ErrRideNotFound       = errors.New("ride not found")
+	ErrRideAccessDenied   = errors.New("ride belongs to another user")
+	ErrRideStatusConflict = errors.New("ride status was changed concurrently")
+
+	ErrEstimateInvalid = errors.New("invalid estimate token")
+	ErrEstimateExpired = errors.New("estimate token expired")
+
+	ErrSurgeNotAcknowledged = errors.New("surge multiplier must be acknowledged")
+
+	ErrStopNotFound    = errors.New("ride stop not found")
+	ErrStopOutOfOrder  = errors.New("previous stops must be reached first")
+	ErrStopsNotReached = errors.New("all stops must be reached before completing the ride")
+
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	ErrInvalidSchedule  = errors.New("invalid scheduled time")
+	ErrRideNotScheduled = errors.New("only scheduled rides can be changed")
+
+	ErrRideNotCompleted = errors.New("only completed rides can be rated")
+	ErrAlreadyRated     = errors.New("ride already rated")
+
+	ErrInvalidTransition   = errors.New("invalid ride status transition")
+	ErrTransitionForbidden = errors.New("ride status transition is not allowed for this actor")
+)
 
 var (
 	ErrInternalServiceError = errors.New("internal service error")
@@ -16,4 +40,26 @@ var (
 	ErrDriverNotFound       = errors.New("driver not found")
 	ErrDriverOnline         = errors.New("driver is online")
 	ErrDriverStatusNotAllow = errors.New("the status of the driver does not allow")
+	ErrSessionNotFound      = errors.New("driver session not found")
+
+	ErrDriverDocumentsExpired = errors.New("driver documents are expired")
+
+	ErrInvalidPeriod = errors.New("period must be day, week or month")
+)
+
+var (
+	ErrOfferNotFound = errors.New("ride offer not found")
+)
+
+var (
+	ErrLocationRateLimited = errors.New("location updates are too frequent")
+)
+
+var (
+	ErrQueueNotFound = errors.New("queue not found")
+)
+
+var (
+	ErrTariffNotFound = errors.New("tariff not found")
+	ErrInvalidTariff  = errors.New("invalid tariff")
 )
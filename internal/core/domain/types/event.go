@@ -0,0 +1,38 @@
+package types
+
+var (
+	RideEventRequested       = "RIDE_REQUESTED"
+	RideEventDriverMatched   = "DRIVER_MATCHED"
+	RideEventDriverArrived   = "DRIVER_ARRIVED"
+	RideEventStarted         = "RIDE_STARTED"
+	RideEventCompleted       = "RIDE_COMPLETED"
+	RideEventCancelled       = "RIDE_CANCELLED"
+	RideEventStatusChanged   = "STATUS_CHANGED"
+	RideEventLocationUpdated = "LOCATION_UPDATED"
+	RideEventFareAdjusted    = "FARE_ADJUSTED"
+	RideEventRedispatched    = "RIDE_REDISPATCHED"
+	RideEventScheduled       = "RIDE_SCHEDULED"
+	RideEventRescheduled     = "RIDE_RESCHEDULED"
+	RideEventStopReached     = "STOP_REACHED"
+	RideEventRated           = "RIDE_RATED"
+)
+
+// RideEventForStatus возвращает тип события для перехода в status
+func RideEventForStatus(status string) string {
+	switch status {
+	case RideStatusREQUESTED:
+		return RideEventRequested
+	case RideStatusMATCHED:
+		return RideEventDriverMatched
+	case RideStatusARRIVED:
+		return RideEventDriverArrived
+	case RideStatusIN_PROGRESS:
+		return RideEventStarted
+	case RideStatusCOMPLETED:
+		return RideEventCompleted
+	case RideStatusCANCELLED:
+		return RideEventCancelled
+	default:
+		return RideEventStatusChanged
+	}
+}
@@ -10,4 +10,6 @@ var (
 	RideTypeECONOMY = "ECONOMY"
 	RideTypePREMIUM = "PREMIUM"
 	RideTypeXL      = "XL"
+
+	RideTypes = []string{RideTypeECONOMY, RideTypePREMIUM, RideTypeXL}
 )
@@ -2,6 +2,6 @@ package types
 
 var (
 	RoleCustomer = "PASSENGER"
-	RoleDriver   = "Driver"
-	RoleAdmin    = "Admin"
+	RoleDriver   = "DRIVER"
+	RoleAdmin    = "ADMIN"
 )
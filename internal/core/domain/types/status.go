@@ -6,6 +6,7 @@ var (
 )
 
 var (
+	RideStatusSCHEDULED   = "SCHEDULED"
 	RideStatusREQUESTED   = "REQUESTED"
 	RideStatusMATCHED     = "MATCHED"
 	RideStatusEN_ROUTE    = "EN_ROUTE"
@@ -15,9 +16,25 @@ var (
 	RideStatusCANCELLED   = "CANCELLED"
 )
 
+var RideStatuses = []string{
+	RideStatusSCHEDULED, RideStatusREQUESTED, RideStatusMATCHED, RideStatusEN_ROUTE,
+	RideStatusARRIVED, RideStatusIN_PROGRESS, RideStatusCOMPLETED, RideStatusCANCELLED,
+}
+
 var (
 	DriverStatusOffline   = "OFFLINE"
 	DriverStatusAvailable = "AVAILABLE"
 	DriverStatusBusy      = "BUSY"
 	DriverStatusEnRoute   = "EN_ROUTE"
 )
+
+var (
+	EarningsPeriodDay   = "day"
+	EarningsPeriodWeek  = "week"
+	EarningsPeriodMonth = "month"
+)
+
+var (
+	CancelReasonNoDrivers        = "NO_DRIVERS_AVAILABLE"
+	ReleaseReasonRideUnavailable = "RIDE_NO_LONGER_AVAILABLE"
+)
@@ -32,53 +32,171 @@ type PassengerWSManager interface {
 type RideService interface {
 	StartService(ctx context.Context)
 	CreateNewRide(ctx context.Context, r models.CreateRideRequest) (models.CreateRideResponse, error)
+	EstimateRide(ctx context.Context, req models.EstimateRideRequest) (models.EstimateRideResponse, error)
 	CloseRide(ctx context.Context, req models.CloseRideRequest) (models.CloseRideResponse, error)
+	UpdateScheduledRide(ctx context.Context, req models.UpdateRideRequest) (models.CreateRideResponse, error)
+	GetRideEvents(ctx context.Context, rideID string) ([]models.RideEvent, error)
+	ListRides(ctx context.Context, filter models.RideFilter) (models.RidesPage, error)
+	GetRideDetail(ctx context.Context, rideID string) (models.RideDetail, error)
+	RateRide(ctx context.Context, req models.RateRideRequest) (models.RateRideResponse, error)
 }
 
 type RideProducer interface {
-	Producer(exName, queue string, message []byte) error
+	Producer(ctx context.Context, exName, routingKey string, message []byte) error
 }
 
 type LocationSubscriber interface {
-	Subscribe(ctx context.Context) (<-chan models.DriverLocationUpdate, error)
-	Start(ctx context.Context) error
+	Subscribe(ctx context.Context) (<-chan models.Delivery[models.DriverLocationUpdate], error)
 }
 
 type DriverMatchSubscriber interface {
-	Subscribe(ctx context.Context) (<-chan models.DriverResponseEvent, error)
-	Start(ctx context.Context) error
+	Subscribe(ctx context.Context) (<-chan models.Delivery[models.DriverResponseEvent], error)
 }
 type RideStatusSubscriber interface {
-	Subscribe(ctx context.Context) (<-chan models.RideStatusEvent, error)
-	Start(ctx context.Context) error
+	Subscribe(ctx context.Context) (<-chan models.Delivery[models.RideStatusEvent], error)
 }
 
 type RideRepository interface {
 	CreateNewRide(ctx context.Context, ride models.Ride) (string, error)
 	GetRide(ctx context.Context, id string) (models.Ride, error)
-	UpdateRide(ctx context.Context, rideID string, newStatus string, reason string, t *time.Time) error
+	UpdateRide(ctx context.Context, rideID, expected, newStatus, reason string, t *time.Time) error
 	UpdateMatchedRide(ctx context.Context, rideID, driverID string, matchedAt time.Time) error
 	GenerateRideNumber(ctx context.Context) (int, error)
+	GetActiveRideID(ctx context.Context, driverID string) (string, error)
+	SetFinalFare(ctx context.Context, rideID string, fare float64) error
+	ListExpiredDispatches(ctx context.Context, before time.Time, limit int) ([]models.RideDispatch, error)
+	MarkRedispatched(ctx context.Context, rideID string) error
+	ListDueScheduled(ctx context.Context, before time.Time, limit int) ([]models.Ride, error)
+	PromoteScheduled(ctx context.Context, rideID string, requestedAt time.Time) error
+	UpdateScheduled(ctx context.Context, ride models.Ride) error
+	ListByPassenger(ctx context.Context, filter models.RideFilter, after *models.RideCursor) ([]models.RideSummary, error)
+	ListByDriver(ctx context.Context, filter models.RideFilter, after *models.RideCursor) ([]models.RideSummary, error)
+	GetDetail(ctx context.Context, rideID string) (models.RideDetail, error)
+}
+
+type RideStopRepository interface {
+	Insert(ctx context.Context, rideID string, seq int, coordinateID string) error
+	ListByRide(ctx context.Context, rideID string) ([]models.RideStop, error)
+	MarkReached(ctx context.Context, rideID string, seq int, at time.Time) error
+}
+
+type RatingRepository interface {
+	Insert(ctx context.Context, r models.RideRating) error
+	LockRatee(ctx context.Context, rateeID string) error
+	WeightedScores(ctx context.Context, rateeID string, window int, decay float64) (float64, float64, int, error)
+	SetDriverRating(ctx context.Context, driverID string, rating float64) error
+	SetPassengerRating(ctx context.Context, passengerID string, rating float64, count int) error
+}
+
+type RideEventRepository interface {
+	Insert(ctx context.Context, rideID, eventType string, data models.RideEventData) error
+	ListByRide(ctx context.Context, rideID string) ([]models.RideEvent, error)
 }
 
 type CoordinatesRepository interface {
 	CreateNewCoordinate(ctx context.Context, c models.Coordinate) (string, error)
 	GetCoordinate(ctx context.Context, id string) (models.Coordinate, error)
+	ResetCurrentCoordinates(ctx context.Context, entityID, entityType string) error
+	UpdateCurrentCoordinate(ctx context.Context, entityID, entityType string, lat, lng float64) (string, error)
 }
 
 //dal ports
 
 type DalService interface {
+	StartService(ctx context.Context)
 	CreateNewDriver(ctx context.Context, newDriver models.Driver) error
 	StatusOnline(ctx context.Context, id string, loc models.Position) (string, error)
 	StatusClose(ctx context.Context, id string) (*models.DriverInfoClosed, error)
+	UpdateLocation(ctx context.Context, driverID string, loc models.LocationUpdate) (string, error)
+	EnRouteRide(ctx context.Context, driverID, rideID string) (models.RideProgressResponse, error)
+	ArriveRide(ctx context.Context, driverID, rideID string) (models.RideProgressResponse, error)
+	StartRide(ctx context.Context, driverID, rideID string) (models.RideProgressResponse, error)
+	CompleteRide(ctx context.Context, driverID, rideID string) (models.RideProgressResponse, error)
+	ReachStop(ctx context.Context, driverID, rideID string, seq int) (models.RideProgressResponse, error)
+	ListRides(ctx context.Context, filter models.RideFilter) (models.RidesPage, error)
+	GetEarnings(ctx context.Context, driverID, period string) (models.DriverEarnings, error)
+}
+
+type LocationRepository interface {
+	Insert(ctx context.Context, l models.LocationHistory) error
+	ListByRide(ctx context.Context, rideID string, from, to time.Time) ([]models.LocationHistory, error)
+}
+
+type MatchingService interface {
+	StartService(ctx context.Context)
+	HandleOfferResponse(ctx context.Context, driverID, offerID, rideID string, accepted bool) error
+}
+
+type RideRequestSubscriber interface {
+	Subscribe(ctx context.Context) (<-chan models.Delivery[models.RideRequestRideType], error)
+}
+
+type DriverNotifier interface {
+	SendRideOffer(ctx context.Context, driverID string, offer models.RideOffer) error
+	SendRideCancelled(ctx context.Context, driverID string, event models.DriverReleaseEvent) error
+}
+
+type DriverReleaseSubscriber interface {
+	Subscribe(ctx context.Context) (<-chan models.Delivery[models.DriverReleaseEvent], error)
 }
 
 type DriversRepository interface {
 	Insert(ctx context.Context, driver models.Driver) error
 	Get(ctx context.Context, id string) (models.Driver, error)
 	UpdateStatus(ctx context.Context, id, status string) error
+	UpdateStatusFrom(ctx context.Context, id, from, to string) error
+	FindAvailableNearby(ctx context.Context, search models.DriverSearch) ([]models.DriverCandidate, error)
 	InsertSession(ctx context.Context, id string) (string, error)
 	CloseSession(ctx context.Context, id string) error
 	GetLastActiveSession(ctx context.Context, driverID string) (models.DriverSession, error)
+	CreditRide(ctx context.Context, id string, fare float64) error
+	SumEarnings(ctx context.Context, driverID string, from, to time.Time) (int, float64, error)
+	ListSessions(ctx context.Context, driverID string, from, to time.Time) ([]models.DriverSession, error)
+}
+
+type AdminService interface {
+	GetOverview(ctx context.Context) (models.OverviewMetrics, error)
+	GetActiveRides(ctx context.Context, page, pageSize int) (models.ActiveRidesPage, error)
+	ListDeadLetters(ctx context.Context, queue string, limit int) ([]models.DeadLetter, error)
+	ReplayDeadLetters(ctx context.Context, queue string, limit int) (models.ReplayResponse, error)
+	ListParkedOutbox(ctx context.Context, limit int) ([]models.ParkedMessage, error)
+	ReplayParkedOutbox(ctx context.Context, limit int) (models.ReplayResponse, error)
+	ListTariffs(ctx context.Context) ([]models.Tariff, error)
+	CreateTariff(ctx context.Context, req models.CreateTariffRequest) (models.Tariff, error)
+}
+
+type AdminRepository interface {
+	GetRideMetrics(ctx context.Context) (models.Metrics, error)
+	GetOnlineDrivers(ctx context.Context) (map[string]int, map[string]int, error)
+	GetActiveRides(ctx context.Context, limit, offset int) ([]models.ActiveRide, int, error)
+}
+
+type OutboxRepository interface {
+	Insert(ctx context.Context, m models.OutboxMessage) error
+	ClaimPending(ctx context.Context, limit int, leaseUntil time.Time) ([]models.OutboxMessage, error)
+	MarkSent(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id, reason string, nextAttemptAt time.Time) error
+	MarkRejected(ctx context.Context, id, reason string, nextAttemptAt time.Time) error
+	Park(ctx context.Context, id, reason string) error
+	ListParked(ctx context.Context, limit int) ([]models.ParkedMessage, error)
+	Unpark(ctx context.Context, limit int) (int, error)
+}
+
+type TariffRepository interface {
+	List(ctx context.Context) ([]models.Tariff, error)
+	Insert(ctx context.Context, t models.Tariff) (models.Tariff, error)
+}
+
+type SurgeRepository interface {
+	ZoneSupplyDemand(ctx context.Context, zone string, since time.Time) (int, int, error)
+}
+
+// RoutingProvider строит маршрут между точками; реализации — прямая по сфере или внешний маршрутизатор
+type RoutingProvider interface {
+	Route(ctx context.Context, from, to models.Position) (models.Route, error)
+}
+
+type DeadLetterQueue interface {
+	Peek(ctx context.Context, queue string, limit int) ([]models.DeadLetter, error)
+	Replay(ctx context.Context, queue string, limit int) (int, error)
 }
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/internal/core/ports"
+	"ride-hail/pkg/logger"
+	"strings"
+	"time"
+)
+
+type AdminService struct {
+	log     *logger.Logger
+	repo    ports.AdminRepository
+	tariffs ports.TariffRepository
+	dlq     ports.DeadLetterQueue
+	outbox  ports.OutboxRepository
+}
+
+func NewAdminService(log *logger.Logger, repo ports.AdminRepository, tariffs ports.TariffRepository, dlq ports.DeadLetterQueue, outbox ports.OutboxRepository) *AdminService {
+	return &AdminService{
+		log:     log,
+		repo:    repo,
+		tariffs: tariffs,
+		dlq:     dlq,
+		outbox:  outbox,
+	}
+}
+
+func (svc *AdminService) GetOverview(ctx context.Context) (models.OverviewMetrics, error) {
+	log := svc.log.Func("AdminService.GetOverview")
+
+	metrics, err := svc.repo.GetRideMetrics(ctx)
+	if err != nil {
+		log.Error(ctx, action.AdminOverview, "error when getting ride metrics", "error", err)
+		return models.OverviewMetrics{}, types.ErrInternalServiceError
+	}
+
+	byStatus, byVehicle, err := svc.repo.GetOnlineDrivers(ctx)
+	if err != nil {
+		log.Error(ctx, action.AdminOverview, "error when getting online drivers", "error", err)
+		return models.OverviewMetrics{}, types.ErrInternalServiceError
+	}
+
+	for _, count := range byStatus {
+		metrics.OnlineDrivers += count
+	}
+	metrics.AvailableDrivers = byStatus[types.DriverStatusAvailable]
+	metrics.BusyDrivers = byStatus[types.DriverStatusBusy] + byStatus[types.DriverStatusEnRoute]
+
+	return models.OverviewMetrics{
+		Timestamp:          time.Now(),
+		Metrics:            metrics,
+		DriverDistribution: byVehicle,
+	}, nil
+}
+
+func (svc *AdminService) GetActiveRides(ctx context.Context, page, pageSize int) (models.ActiveRidesPage, error) {
+	log := svc.log.Func("AdminService.GetActiveRides")
+
+	rides, total, err := svc.repo.GetActiveRides(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		log.Error(ctx, action.AdminActiveRides, "error when getting active rides", "error", err)
+		return models.ActiveRidesPage{}, types.ErrInternalServiceError
+	}
+
+	return models.ActiveRidesPage{
+		Rides:      rides,
+		TotalCount: total,
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (svc *AdminService) ListDeadLetters(ctx context.Context, queue string, limit int) ([]models.DeadLetter, error) {
+	log := svc.log.Func("AdminService.ListDeadLetters")
+
+	letters, err := svc.dlq.Peek(ctx, queue, limit)
+	if err != nil {
+		if errors.Is(err, types.ErrQueueNotFound) {
+			return nil, err
+		}
+		log.Error(ctx, action.AdminDeadLetters, "error when reading dead letters", "queue", queue, "error", err)
+		return nil, types.ErrInternalServiceError
+	}
+
+	return letters, nil
+}
+
+func (svc *AdminService) ReplayDeadLetters(ctx context.Context, queue string, limit int) (models.ReplayResponse, error) {
+	log := svc.log.Func("AdminService.ReplayDeadLetters")
+
+	n, err := svc.dlq.Replay(ctx, queue, limit)
+	if err != nil {
+		if errors.Is(err, types.ErrQueueNotFound) {
+			return models.ReplayResponse{}, err
+		}
+		log.Error(ctx, action.AdminDeadLetters, "error when replaying dead letters", "queue", queue, "replayed", n, "error", err)
+		return models.ReplayResponse{}, types.ErrInternalServiceError
+	}
+
+	log.Info(ctx, action.AdminDeadLetters, "dead letters replayed", "queue", queue, "replayed", n)
+	return models.ReplayResponse{Queue: queue, Replayed: n}, nil
+}
+
+func (svc *AdminService) ListParkedOutbox(ctx context.Context, limit int) ([]models.ParkedMessage, error) {
+	log := svc.log.Func("AdminService.ListParkedOutbox")
+
+	messages, err := svc.outbox.ListParked(ctx, limit)
+	if err != nil {
+		log.Error(ctx, action.AdminOutbox, "error when listing parked outbox messages", "error", err)
+		return nil, types.ErrInternalServiceError
+	}
+
+	return messages, nil
+}
+
+// ReplayParkedOutbox возвращает запаркованные сообщения relay; отправит их любой запущенный ride или driver сервис
+func (svc *AdminService) ReplayParkedOutbox(ctx context.Context, limit int) (models.ReplayResponse, error) {
+	log := svc.log.Func("AdminService.ReplayParkedOutbox")
+
+	n, err := svc.outbox.Unpark(ctx, limit)
+	if err != nil {
+		log.Error(ctx, action.AdminOutbox, "error when unparking outbox messages", "error", err)
+		return models.ReplayResponse{}, types.ErrInternalServiceError
+	}
+
+	log.Info(ctx, action.AdminOutbox, "parked outbox messages replayed", "replayed", n)
+	return models.ReplayResponse{Queue: "outbox", Replayed: n}, nil
+}
+
+func (svc *AdminService) ListTariffs(ctx context.Context) ([]models.Tariff, error) {
+	log := svc.log.Func("AdminService.ListTariffs")
+
+	tariffs, err := svc.tariffs.List(ctx)
+	if err != nil {
+		log.Error(ctx, action.AdminTariffs, "error when listing tariffs", "error", err)
+		return nil, types.ErrInternalServiceError
+	}
+
+	return tariffs, nil
+}
+
+// CreateTariff добавляет новую версию тарифа; старые версии не меняются, чтобы прошлые цены оставались объяснимыми
+func (svc *AdminService) CreateTariff(ctx context.Context, req models.CreateTariffRequest) (models.Tariff, error) {
+	log := svc.log.Func("AdminService.CreateTariff")
+
+	t := models.Tariff{
+		VehicleType:        req.VehicleType,
+		BaseFare:           req.BaseFare,
+		RatePerKm:          req.RatePerKm,
+		RatePerMin:         req.RatePerMin,
+		MinimumFare:        req.MinimumFare,
+		BookingFee:         req.BookingFee,
+		FreeWaitingMinutes: req.FreeWaitingMinutes,
+		WaitingRatePerMin:  req.WaitingRatePerMin,
+		Currency:           req.Currency,
+		EffectiveFrom:      time.Now(),
+	}
+	if req.EffectiveFrom != nil {
+		t.EffectiveFrom = *req.EffectiveFrom
+	}
+
+	if err := validateTariff(t, time.Now()); err != nil {
+		log.Warn(ctx, action.AdminTariffs, "invalid tariff", "error", err)
+		return models.Tariff{}, err
+	}
+
+	t, err := svc.tariffs.Insert(ctx, t)
+	if err != nil {
+		log.Error(ctx, action.AdminTariffs, "error when creating tariff", "error", err)
+		return models.Tariff{}, types.ErrInternalServiceError
+	}
+
+	log.Info(ctx, action.AdminTariffs, "tariff version created", "tariff_id", t.ID, "vehicle_type", t.VehicleType, "effective_from", t.EffectiveFrom)
+	return t, nil
+}
+
+// validateTariff не даёт создать версию с отрицательными суммами или задним числом:
+// поездки прошлого периода уже посчитаны по действовавшей тогда версии
+func validateTariff(t models.Tariff, now time.Time) error {
+	var reasons []string
+
+	if t.BaseFare < 0 || t.RatePerKm < 0 || t.RatePerMin < 0 || t.MinimumFare < 0 || t.BookingFee < 0 || t.WaitingRatePerMin < 0 {
+		reasons = append(reasons, "negative_amount")
+	}
+	if t.FreeWaitingMinutes < 0 {
+		reasons = append(reasons, "negative_free_waiting_minutes")
+	}
+	if t.EffectiveFrom.Before(now.Add(-time.Minute)) {
+		reasons = append(reasons, "effective_from_in_past")
+	}
+
+	if len(reasons) > 0 {
+		return fmt.Errorf("%w: %s", types.ErrInvalidTariff, strings.Join(reasons, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+)
+
+func TestValidateTariff(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	valid := models.Tariff{VehicleType: types.RideTypeECONOMY, BaseFare: 500, RatePerKm: 100, RatePerMin: 50, MinimumFare: 500, EffectiveFrom: now}
+
+	tests := []struct {
+		name       string
+		modify     func(t *models.Tariff)
+		wantReason string
+	}{
+		{name: "valid", modify: func(*models.Tariff) {}},
+		{name: "scheduled for later", modify: func(t *models.Tariff) { t.EffectiveFrom = now.Add(24 * time.Hour) }},
+		{name: "negative rate", modify: func(t *models.Tariff) { t.RatePerKm = -1 }, wantReason: "negative_amount"},
+		{name: "negative free waiting", modify: func(t *models.Tariff) { t.FreeWaitingMinutes = -5 }, wantReason: "negative_free_waiting_minutes"},
+		{name: "backdated", modify: func(t *models.Tariff) { t.EffectiveFrom = now.Add(-time.Hour) }, wantReason: "effective_from_in_past"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tariff := valid
+			tt.modify(&tariff)
+
+			err := validateTariff(tariff, now)
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("validateTariff() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, types.ErrInvalidTariff) || !strings.Contains(err.Error(), tt.wantReason) {
+				t.Fatalf("validateTariff() error = %v, want %v with %q", err, types.ErrInvalidTariff, tt.wantReason)
+			}
+		})
+	}
+}
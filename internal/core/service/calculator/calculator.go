@@ -1,9 +1,8 @@
 package calculator
 
 import (
-	"fmt"
 	"math"
-	"ride-hail/internal/core/domain/types"
+	"ride-hail/internal/core/domain/models"
 )
 
 const earthRadius = 6371.0
@@ -28,26 +27,65 @@ func Duration(dist float64) int {
 	return int((dist / avgSpeedKmH) * 60)
 }
 
-func CalculateFare(rideType string, distanceKm float64, durationMin int) (float64, error) {
-	var baseFare, ratePerKm, ratePerMin float64
-
-	switch rideType {
-	case types.RideTypeECONOMY:
-		baseFare = 500
-		ratePerKm = 100
-		ratePerMin = 50
-	case types.RideTypePREMIUM:
-		baseFare = 800
-		ratePerKm = 120
-		ratePerMin = 60
-	case types.RideTypeXL:
-		baseFare = 1000
-		ratePerKm = 150
-		ratePerMin = 75
-	default:
-		return 0, fmt.Errorf("unknown ride type: %s", rideType)
+// TripOf складывает участки маршрута через все остановки в одну поездку
+func TripOf(legs []models.Route) models.Trip {
+	var trip models.Trip
+	for _, leg := range legs {
+		trip.DistanceKm += leg.DistanceKm
+		trip.DurationMinutes += leg.DurationMinutes
+	}
+	return trip
+}
+
+// CalculateFare считает стоимость по тарифу: не меньше минимальной, умноженную на surge, плюс сервисный сбор
+func CalculateFare(t models.Tariff, trip models.Trip, surge float64) float64 {
+	waiting := max(trip.WaitingMinutes-t.FreeWaitingMinutes, 0)
+
+	total := t.BaseFare + (trip.DistanceKm * t.RatePerKm) + (float64(trip.DurationMinutes) * t.RatePerMin) +
+		(float64(waiting) * t.WaitingRatePerMin)
+	total = max(total, t.MinimumFare)*max(surge, 1) + t.BookingFee
+	return math.Round(total*100) / 100
+}
+
+const (
+	// точки с худшей точностью не учитываются
+	routeMaxAccuracyMeters = 50
+	// смещения меньше этого считаются дрожанием GPS на месте
+	routeMinStepKm = 0.01
+	// быстрее этого машина не едет, такой скачок — ошибка GPS
+	routeMaxSpeedKmH = 200
+)
+
+// RouteDistance суммирует расстояние по точкам маршрута, отбрасывая неточные точки,
+// дрожание на месте и невозможные скачки; points должны быть отсортированы по времени
+func RouteDistance(points []models.LocationHistory) float64 {
+	var (
+		total  float64
+		anchor *models.LocationHistory
+	)
+
+	for i := range points {
+		p := &points[i]
+		if p.AccuracyMeters > routeMaxAccuracyMeters {
+			continue
+		}
+		if anchor == nil {
+			anchor = p
+			continue
+		}
+
+		step := Distance(anchor.Latitude, anchor.Longitude, p.Latitude, p.Longitude)
+		if step < routeMinStepKm {
+			continue
+		}
+
+		if hours := p.RecordedAt.Sub(anchor.RecordedAt).Hours(); hours > 0 && step/hours > routeMaxSpeedKmH {
+			continue
+		}
+
+		total += step
+		anchor = p
 	}
 
-	total := baseFare + (distanceKm * ratePerKm) + (float64(durationMin) * ratePerMin)
-	return total, nil
+	return total
 }
@@ -0,0 +1,189 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+)
+
+var economy = models.Tariff{
+	ID:                 "economy-v1",
+	VehicleType:        types.RideTypeECONOMY,
+	BaseFare:           500,
+	RatePerKm:          100,
+	RatePerMin:         50,
+	MinimumFare:        1000,
+	BookingFee:         150,
+	FreeWaitingMinutes: 3,
+	WaitingRatePerMin:  20,
+	Currency:           "KZT",
+}
+
+func TestCalculateFare(t *testing.T) {
+	tests := []struct {
+		name  string
+		trip  models.Trip
+		surge float64
+		want  float64
+	}{
+		{name: "regular trip", trip: models.Trip{DistanceKm: 5, DurationMinutes: 10}, surge: 1, want: 500 + 500 + 500 + 150},
+		{name: "minimum fare", trip: models.Trip{DistanceKm: 1, DurationMinutes: 2}, surge: 1, want: 1000 + 150},
+		{name: "surge applies before booking fee", trip: models.Trip{DistanceKm: 5, DurationMinutes: 10}, surge: 1.5, want: 1500*1.5 + 150},
+		{name: "surge below one is ignored", trip: models.Trip{DistanceKm: 5, DurationMinutes: 10}, surge: 0.5, want: 1500 + 150},
+		{name: "free waiting", trip: models.Trip{DistanceKm: 5, DurationMinutes: 10, WaitingMinutes: 3}, surge: 1, want: 1500 + 150},
+		{name: "paid waiting", trip: models.Trip{DistanceKm: 5, DurationMinutes: 10, WaitingMinutes: 8}, surge: 1, want: 1500 + 5*20 + 150},
+		{name: "rounded to cents", trip: models.Trip{DistanceKm: 7.3333, DurationMinutes: 15}, surge: 1, want: 2133.33},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateFare(economy, tt.trip, tt.surge); got != tt.want {
+				t.Errorf("CalculateFare() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTripOf(t *testing.T) {
+	trip := TripOf([]models.Route{
+		{DistanceKm: 2.5, DurationMinutes: 6},
+		{DistanceKm: 4, DurationMinutes: 9},
+	})
+	if trip.DistanceKm != 6.5 || trip.DurationMinutes != 15 {
+		t.Errorf("TripOf() = %+v, want 6.5 km, 15 min", trip)
+	}
+}
+
+func TestRouteDistance(t *testing.T) {
+	start := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	// 0.01° широты — около 1.1 км
+	const stepDeg = 0.01
+	step := Distance(43.0, 76.9, 43.0+stepDeg, 76.9)
+
+	point := func(lat float64, after time.Duration, accuracy float64) models.LocationHistory {
+		return models.LocationHistory{Latitude: lat, Longitude: 76.9, AccuracyMeters: accuracy, RecordedAt: start.Add(after)}
+	}
+
+	tests := []struct {
+		name   string
+		points []models.LocationHistory
+		want   float64
+	}{
+		{name: "no points", want: 0},
+		{name: "single point", points: []models.LocationHistory{point(43.0, 0, 5)}, want: 0},
+		{
+			name: "straight line",
+			points: []models.LocationHistory{
+				point(43.0, 0, 5),
+				point(43.0+stepDeg, time.Minute, 5),
+				point(43.0+2*stepDeg, 2*time.Minute, 5),
+			},
+			want: 2 * step,
+		},
+		{
+			name: "inaccurate point skipped",
+			points: []models.LocationHistory{
+				point(43.0, 0, 5),
+				point(43.5, 30*time.Second, 500),
+				point(43.0+stepDeg, time.Minute, 5),
+			},
+			want: step,
+		},
+		{
+			name: "jitter in place ignored",
+			points: []models.LocationHistory{
+				point(43.0, 0, 5),
+				point(43.00001, 10*time.Second, 5),
+				point(43.0, 20*time.Second, 5),
+			},
+			want: 0,
+		},
+		{
+			name: "impossible jump skipped",
+			points: []models.LocationHistory{
+				point(43.0, 0, 5),
+				point(44.0, 5*time.Second, 5),
+				point(43.0+stepDeg, time.Minute, 5),
+			},
+			want: step,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RouteDistance(tt.points); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("RouteDistance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type stubTariffRepository struct {
+	tariffs []models.Tariff
+	calls   int
+}
+
+func (r *stubTariffRepository) List(context.Context) ([]models.Tariff, error) {
+	r.calls++
+	return r.tariffs, nil
+}
+
+func (r *stubTariffRepository) Insert(_ context.Context, t models.Tariff) (models.Tariff, error) {
+	return t, nil
+}
+
+func TestCalculatorTariffVersion(t *testing.T) {
+	switchAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	old := economy
+	old.EffectiveFrom = time.Unix(0, 0).UTC()
+	current := economy
+	current.ID = "economy-v2"
+	current.BaseFare = 600
+	current.EffectiveFrom = switchAt
+
+	repo := &stubTariffRepository{tariffs: []models.Tariff{current, old}}
+	calc := New(repo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		rideTyp string
+		wantID  string
+		wantErr error
+	}{
+		{name: "before switch", at: switchAt.Add(-time.Second), rideTyp: types.RideTypeECONOMY, wantID: "economy-v1"},
+		{name: "at switch", at: switchAt, rideTyp: types.RideTypeECONOMY, wantID: "economy-v2"},
+		{name: "unknown type", at: switchAt, rideTyp: "HELICOPTER", wantErr: types.ErrTariffNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calc.Tariff(ctx, tt.rideTyp, tt.at)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Tariff() error = %v, want %v", err, tt.wantErr)
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("Tariff() = %s, want %s", got.ID, tt.wantID)
+			}
+		})
+	}
+
+	if repo.calls != 1 {
+		t.Errorf("tariffs loaded %d times, want 1 within TTL", repo.calls)
+	}
+
+	fare, err := calc.FareByTariff(ctx, "economy-v1", models.Trip{DistanceKm: 5, DurationMinutes: 10}, 1)
+	if err != nil {
+		t.Fatalf("FareByTariff() error = %v", err)
+	}
+	if fare.Amount != 1650 || fare.TariffID != "economy-v1" || fare.Currency != "KZT" {
+		t.Errorf("FareByTariff() = %+v", fare)
+	}
+}
@@ -0,0 +1,128 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/internal/core/ports"
+	"sync"
+	"time"
+)
+
+// tariffsTTL — через сколько подхватываются тарифы, изменённые в админке
+const tariffsTTL = time.Minute
+
+// Calculator считает стоимость по тарифам из базы, держа их в памяти не дольше tariffsTTL
+type Calculator struct {
+	repo ports.TariffRepository
+
+	mu       sync.RWMutex
+	byType   map[string][]models.Tariff // версии по убыванию effective_from
+	byID     map[string]models.Tariff
+	loadedAt time.Time
+}
+
+func New(repo ports.TariffRepository) *Calculator {
+	return &Calculator{
+		repo: repo,
+	}
+}
+
+// Fare считает стоимость по тарифу, действующему для vehicleType в момент at
+func (c *Calculator) Fare(ctx context.Context, vehicleType string, at time.Time, trip models.Trip, surge float64) (models.Fare, error) {
+	t, err := c.Tariff(ctx, vehicleType, at)
+	if err != nil {
+		return models.Fare{}, err
+	}
+	return fareOf(t, trip, surge), nil
+}
+
+// FareByTariff считает стоимость по конкретной версии тарифа, например той, что записана в поездке
+func (c *Calculator) FareByTariff(ctx context.Context, tariffID string, trip models.Trip, surge float64) (models.Fare, error) {
+	if err := c.load(ctx, false); err != nil {
+		return models.Fare{}, err
+	}
+
+	t, ok := c.lookupID(tariffID)
+	if !ok {
+		// тариф мог появиться после последней загрузки
+		if err := c.load(ctx, true); err != nil {
+			return models.Fare{}, err
+		}
+		if t, ok = c.lookupID(tariffID); !ok {
+			return models.Fare{}, fmt.Errorf("%w: %s", types.ErrTariffNotFound, tariffID)
+		}
+	}
+
+	return fareOf(t, trip, surge), nil
+}
+
+// Tariff возвращает версию тарифа для vehicleType с самым поздним effective_from не позже at
+func (c *Calculator) Tariff(ctx context.Context, vehicleType string, at time.Time) (models.Tariff, error) {
+	if err := c.load(ctx, false); err != nil {
+		return models.Tariff{}, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, t := range c.byType[vehicleType] {
+		if !t.EffectiveFrom.After(at) {
+			return t, nil
+		}
+	}
+	return models.Tariff{}, fmt.Errorf("%w: %s", types.ErrTariffNotFound, vehicleType)
+}
+
+// Invalidate сбрасывает кеш, следующий расчёт перечитает тарифы
+func (c *Calculator) Invalidate() {
+	c.mu.Lock()
+	c.loadedAt = time.Time{}
+	c.mu.Unlock()
+}
+
+func (c *Calculator) load(ctx context.Context, force bool) error {
+	c.mu.RLock()
+	fresh := time.Since(c.loadedAt) < tariffsTTL
+	c.mu.RUnlock()
+
+	if fresh && !force {
+		return nil
+	}
+
+	tariffs, err := c.repo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load tariffs: %w", err)
+	}
+
+	byType := make(map[string][]models.Tariff)
+	byID := make(map[string]models.Tariff, len(tariffs))
+	for _, t := range tariffs {
+		byType[t.VehicleType] = append(byType[t.VehicleType], t)
+		byID[t.ID] = t
+	}
+
+	c.mu.Lock()
+	c.byType = byType
+	c.byID = byID
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Calculator) lookupID(id string) (models.Tariff, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	t, ok := c.byID[id]
+	return t, ok
+}
+
+func fareOf(t models.Tariff, trip models.Trip, surge float64) models.Fare {
+	return models.Fare{
+		Amount:          CalculateFare(t, trip, surge),
+		Currency:        t.Currency,
+		TariffID:        t.ID,
+		SurgeMultiplier: max(surge, 1),
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"time"
+)
+
+// ListRides отдаёт страницу поездок водителя filter.DriverID, курсор тот же, что и у истории пассажира
+func (svc *DalService) ListRides(ctx context.Context, filter models.RideFilter) (models.RidesPage, error) {
+	log := svc.log.Func("DalService.ListRides")
+
+	var after *models.RideCursor
+	if filter.Cursor != "" {
+		c, err := decodeRideCursor(filter.Cursor)
+		if err != nil {
+			log.Warn(ctx, action.DriverHistory, "invalid cursor", "cursor", filter.Cursor)
+			return models.RidesPage{}, err
+		}
+		after = &c
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+
+	rides, err := svc.repo.ride.ListByDriver(ctx, filter, after)
+	if err != nil {
+		log.Error(ctx, action.DriverHistory, "error listing driver rides", "error", err)
+		return models.RidesPage{}, types.ErrInternalServiceError
+	}
+
+	return ridesPage(rides, limit), nil
+}
+
+// GetEarnings считает заработок за текущие сутки, неделю (с понедельника) или месяц по UTC.
+// Смены, начатые до периода, учитываются только той частью, что попала в период.
+func (svc *DalService) GetEarnings(ctx context.Context, driverID, period string) (models.DriverEarnings, error) {
+	log := svc.log.Func("DalService.GetEarnings")
+
+	now := time.Now().UTC()
+	from, err := periodStart(now, period)
+	if err != nil {
+		return models.DriverEarnings{}, err
+	}
+
+	rides, earnings, err := svc.repo.driver.SumEarnings(ctx, driverID, from, now)
+	if err != nil {
+		log.Error(ctx, action.DriverHistory, "error summing driver earnings", "error", err)
+		return models.DriverEarnings{}, types.ErrInternalServiceError
+	}
+
+	sessions, err := svc.repo.driver.ListSessions(ctx, driverID, from, now)
+	if err != nil {
+		log.Error(ctx, action.DriverHistory, "error listing driver sessions", "error", err)
+		return models.DriverEarnings{}, types.ErrInternalServiceError
+	}
+
+	resp := models.DriverEarnings{
+		DriverID:       driverID,
+		Period:         period,
+		From:           from,
+		To:             now,
+		RidesCompleted: rides,
+		TotalEarnings:  earnings,
+		Sessions:       make([]models.SessionEarnings, 0, len(sessions)),
+	}
+
+	for _, s := range sessions {
+		item := models.SessionEarnings{
+			SessionID:      s.ID,
+			StartedAt:      s.StartedAt,
+			RidesCompleted: s.TotalRides,
+			Earnings:       s.TotalEarnings,
+		}
+
+		end := now
+		if !s.EndedAt.IsZero() {
+			item.EndedAt = &s.EndedAt
+			end = s.EndedAt
+		}
+		item.DurationHours = sessionHours(s.StartedAt, end, from, now)
+
+		resp.Sessions = append(resp.Sessions, item)
+	}
+
+	return resp, nil
+}
+
+// sessionHours — время смены [start, end), попавшее в период [from, to)
+func sessionHours(start, end, from, to time.Time) float64 {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours()
+}
+
+func periodStart(now time.Time, period string) (time.Time, error) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case types.EarningsPeriodDay:
+		return day, nil
+	case types.EarningsPeriodWeek:
+		// Weekday: воскресенье — 0, неделя начинается с понедельника
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7), nil
+	case types.EarningsPeriodMonth:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	default:
+		return time.Time{}, types.ErrInvalidPeriod
+	}
+}
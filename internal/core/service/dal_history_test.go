@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ride-hail/internal/core/domain/types"
+)
+
+func TestPeriodStart(t *testing.T) {
+	// среда
+	now := time.Date(2024, 5, 15, 17, 42, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		period  string
+		want    time.Time
+		wantErr error
+	}{
+		{name: "day", now: now, period: types.EarningsPeriodDay, want: time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)},
+		{name: "week from monday", now: now, period: types.EarningsPeriodWeek, want: time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{name: "week on monday", now: time.Date(2024, 5, 13, 0, 0, 1, 0, time.UTC), period: types.EarningsPeriodWeek, want: time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{name: "week on sunday", now: time.Date(2024, 5, 19, 23, 59, 0, 0, time.UTC), period: types.EarningsPeriodWeek, want: time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{name: "week across month", now: time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), period: types.EarningsPeriodWeek, want: time.Date(2024, 5, 27, 0, 0, 0, 0, time.UTC)},
+		{name: "month", now: now, period: types.EarningsPeriodMonth, want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "unknown", now: now, period: "year", wantErr: types.ErrInvalidPeriod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := periodStart(tt.now, tt.period)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("periodStart() error = %v, want %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("periodStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionHours(t *testing.T) {
+	from := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	to := from.Add(12 * time.Hour)
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       float64
+	}{
+		{name: "inside period", start: from.Add(time.Hour), end: from.Add(4 * time.Hour), want: 3},
+		{name: "started before period", start: from.Add(-5 * time.Hour), end: from.Add(2 * time.Hour), want: 2},
+		{name: "still open", start: from.Add(10 * time.Hour), end: to.Add(time.Hour), want: 2},
+		{name: "covers whole period", start: from.Add(-time.Hour), end: to.Add(time.Hour), want: 12},
+		{name: "ended before period", start: from.Add(-3 * time.Hour), end: from.Add(-time.Hour), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sessionHours(tt.start, tt.end, from, to); got != tt.want {
+				t.Errorf("sessionHours() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/logger"
+)
+
+func (svc *DalService) StartService(ctx context.Context) {
+	log := svc.log.Func("DalService.StartService")
+
+	go runWithRetry(ctx, svc.log, svc.driverReleases, "DalService.driverReleases")
+
+	log.Debug(ctx, action.UpdateStatus, "DalService started")
+	<-ctx.Done()
+	log.Debug(ctx, action.UpdateStatus, "DalService stopping")
+}
+
+func (svc *DalService) driverReleases(ctx context.Context) error {
+	log := svc.log.Func("DalService.driverReleases")
+
+	ch, err := svc.releases.Subscribe(ctx)
+	if err != nil {
+		log.Error(ctx, action.UpdateStatus, "failed to subscribe to driver releases", "error", err)
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug(ctx, action.UpdateStatus, "driver releases stopped")
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				log.Debug(ctx, action.UpdateStatus, "driver releases channel closed")
+				return fmt.Errorf("driver releases channel closed")
+			}
+			msg.Done(svc.releaseDriver(ctx, msg.Event))
+		}
+	}
+}
+
+// releaseDriver возвращает водителя в AVAILABLE, если он всё ещё едет к пассажиру этой поездки,
+// и сообщает ему об отмене. Повторное или запоздавшее событие ничего не меняет
+func (svc *DalService) releaseDriver(ctx context.Context, ev models.DriverReleaseEvent) error {
+	log := svc.log.Func("DalService.releaseDriver")
+	ctx = logger.WithRequestID(ctx, ev.CorrelationID)
+
+	// водителя уже отпустили и он взял другую поездку
+	if activeID, err := svc.repo.ride.GetActiveRideID(ctx, ev.DriverID); err == nil && activeID != ev.RideID {
+		log.Debug(ctx, action.UpdateStatus, "driver is busy with another ride", "driver_id", ev.DriverID, "ride_id", ev.RideID)
+		return nil
+	} else if err != nil && !errors.Is(err, types.ErrRideNotFound) {
+		log.Error(ctx, action.UpdateStatus, "error getting active ride", "error", err)
+		return err
+	}
+
+	if err := svc.repo.driver.UpdateStatusFrom(ctx, ev.DriverID, types.DriverStatusEnRoute, types.DriverStatusAvailable); err != nil {
+		if errors.Is(err, types.ErrDriverStatusNotAllow) {
+			log.Debug(ctx, action.UpdateStatus, "driver is not en route, nothing to release", "driver_id", ev.DriverID)
+			return nil
+		}
+		log.Error(ctx, action.UpdateStatus, "error releasing driver", "error", err)
+		return err
+	}
+
+	log.Info(ctx, action.UpdateStatus, "driver released", "driver_id", ev.DriverID, "ride_id", ev.RideID, "reason", ev.Reason)
+
+	if err := svc.notifier.SendRideCancelled(ctx, ev.DriverID, ev); err != nil {
+		log.Warn(ctx, action.UpdateStatus, "failed to notify driver about cancelled ride", "driver_id", ev.DriverID, "error", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/state"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/internal/core/service/calculator"
+	"ride-hail/pkg/logger"
+	"slices"
+	"time"
+)
+
+// rideStep описывает шаг поездки, который выполняет водитель
+type rideStep struct {
+	to           string
+	driverStatus string
+	message      string
+}
+
+var (
+	stepEnRoute = rideStep{
+		to:           types.RideStatusEN_ROUTE,
+		driverStatus: types.DriverStatusEnRoute,
+		message:      "Passenger has been notified that you are on the way",
+	}
+	stepArrived = rideStep{
+		to:           types.RideStatusARRIVED,
+		driverStatus: types.DriverStatusEnRoute,
+		message:      "Passenger has been notified of your arrival",
+	}
+	stepStart = rideStep{
+		to:           types.RideStatusIN_PROGRESS,
+		driverStatus: types.DriverStatusBusy,
+		message:      "Ride started successfully",
+	}
+	stepComplete = rideStep{
+		to:           types.RideStatusCOMPLETED,
+		driverStatus: types.DriverStatusAvailable,
+		message:      "Ride completed successfully",
+	}
+)
+
+// EnRouteRide отмечает, что водитель выехал к точке подачи
+func (svc *DalService) EnRouteRide(ctx context.Context, driverID, rideID string) (models.RideProgressResponse, error) {
+	return svc.progressRide(ctx, driverID, rideID, stepEnRoute)
+}
+
+func (svc *DalService) ArriveRide(ctx context.Context, driverID, rideID string) (models.RideProgressResponse, error) {
+	return svc.progressRide(ctx, driverID, rideID, stepArrived)
+}
+
+func (svc *DalService) StartRide(ctx context.Context, driverID, rideID string) (models.RideProgressResponse, error) {
+	return svc.progressRide(ctx, driverID, rideID, stepStart)
+}
+
+func (svc *DalService) CompleteRide(ctx context.Context, driverID, rideID string) (models.RideProgressResponse, error) {
+	return svc.progressRide(ctx, driverID, rideID, stepComplete)
+}
+
+// ReachStop отмечает промежуточную остановку; остановки проходятся по порядку и только во время поездки
+func (svc *DalService) ReachStop(ctx context.Context, driverID, rideID string, seq int) (models.RideProgressResponse, error) {
+	log := svc.log.Func("DalService.ReachStop")
+
+	ride, err := svc.repo.ride.GetRide(ctx, rideID)
+	if err != nil {
+		if errors.Is(err, types.ErrRideNotFound) {
+			log.Warn(ctx, action.RideProgress, "ride not found", "ride_id", rideID)
+			return models.RideProgressResponse{}, types.ErrRideNotFound
+		}
+		log.Error(ctx, action.RideProgress, "error when getting ride", "ride_id", rideID, "error", err)
+		return models.RideProgressResponse{}, types.ErrInternalServiceError
+	}
+
+	if ride.DriverID != driverID {
+		log.Warn(ctx, action.RideProgress, "ride is assigned to another driver", "ride_id", rideID)
+		return models.RideProgressResponse{}, types.ErrRideAccessDenied
+	}
+
+	if ride.Status != types.RideStatusIN_PROGRESS {
+		log.Warn(ctx, action.RideProgress, "stops can be reached only during the ride", "ride_id", rideID, "status", ride.Status)
+		return models.RideProgressResponse{}, fmt.Errorf("%w: ride is %s", types.ErrInvalidTransition, ride.Status)
+	}
+
+	stops, err := svc.repo.stop.ListByRide(ctx, rideID)
+	if err != nil {
+		log.Error(ctx, action.RideProgress, "error when getting ride stops", "ride_id", rideID, "error", err)
+		return models.RideProgressResponse{}, types.ErrInternalServiceError
+	}
+
+	idx := slices.IndexFunc(stops, func(s models.RideStop) bool { return s.Seq == seq })
+	if idx < 0 {
+		log.Warn(ctx, action.RideProgress, "stop not found", "ride_id", rideID, "seq", seq)
+		return models.RideProgressResponse{}, types.ErrStopNotFound
+	}
+	stop := stops[idx]
+
+	resp := models.RideProgressResponse{
+		RideID:  rideID,
+		Status:  ride.Status,
+		Message: fmt.Sprintf("Stop %d of %d reached", seq, len(stops)),
+	}
+
+	// повторная отметка ничего не меняет
+	if stop.ReachedAt != nil {
+		resp.Timestamp = *stop.ReachedAt
+		return resp, nil
+	}
+
+	for _, prev := range stops[:idx] {
+		if prev.ReachedAt == nil {
+			log.Warn(ctx, action.RideProgress, "previous stop is not reached", "ride_id", rideID, "seq", seq, "pending", prev.Seq)
+			return models.RideProgressResponse{}, types.ErrStopOutOfOrder
+		}
+	}
+
+	now := time.Now()
+	resp.Timestamp = now
+
+	fn := func(ctx context.Context) error {
+		if err := svc.repo.stop.MarkReached(ctx, rideID, seq, now); err != nil {
+			return err
+		}
+
+		return svc.repo.event.Insert(ctx, rideID, types.RideEventStopReached, models.RideEventData{
+			DriverID:      driverID,
+			Location:      &models.LocationDriver{Lat: stop.Location.Lat, Lng: stop.Location.Lng},
+			StopSeq:       seq,
+			Timestamp:     now,
+			CorrelationID: logger.GetRequestID(ctx),
+		})
+	}
+
+	if err = svc.txm.Do(ctx, fn); err != nil {
+		log.Error(ctx, action.RideProgress, "error saving reached stop", "ride_id", rideID, "seq", seq, "error", err)
+		return models.RideProgressResponse{}, types.ErrInternalServiceError
+	}
+
+	log.Info(ctx, action.RideProgress, "ride stop reached", "ride_id", rideID, "seq", seq)
+	return resp, nil
+}
+
+// checkStopsReached не даёт завершить поездку, пока водитель не отметил все промежуточные остановки
+func (svc *DalService) checkStopsReached(ctx context.Context, rideID string) error {
+	stops, err := svc.repo.stop.ListByRide(ctx, rideID)
+	if err != nil {
+		return err
+	}
+
+	var pending []int
+	for _, s := range stops {
+		if s.ReachedAt == nil {
+			pending = append(pending, s.Seq)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending stops %v", types.ErrStopsNotReached, pending)
+	}
+	return nil
+}
+
+func (svc *DalService) progressRide(ctx context.Context, driverID, rideID string, step rideStep) (models.RideProgressResponse, error) {
+	log := svc.log.Func("DalService.progressRide")
+
+	ride, err := svc.repo.ride.GetRide(ctx, rideID)
+	if err != nil {
+		if errors.Is(err, types.ErrRideNotFound) {
+			log.Warn(ctx, action.RideProgress, "ride not found", "ride_id", rideID)
+			return models.RideProgressResponse{}, types.ErrRideNotFound
+		}
+		log.Error(ctx, action.RideProgress, "error when getting ride", "ride_id", rideID, "error", err)
+		return models.RideProgressResponse{}, types.ErrInternalServiceError
+	}
+
+	if ride.DriverID != driverID {
+		log.Warn(ctx, action.RideProgress, "ride is assigned to another driver", "ride_id", rideID)
+		return models.RideProgressResponse{}, types.ErrRideAccessDenied
+	}
+
+	if err = state.CanTransition(ride.Status, step.to, state.ActorDriver); err != nil {
+		log.Warn(ctx, action.RideProgress, "ride status does not allow this step", "ride_id", rideID, "error", err)
+		return models.RideProgressResponse{}, err
+	}
+
+	if step.to == types.RideStatusCOMPLETED {
+		if err = svc.checkStopsReached(ctx, rideID); err != nil {
+			if errors.Is(err, types.ErrStopsNotReached) {
+				log.Warn(ctx, action.RideProgress, "ride has unreached stops", "ride_id", rideID, "error", err)
+				return models.RideProgressResponse{}, err
+			}
+			log.Error(ctx, action.RideProgress, "error when getting ride stops", "ride_id", rideID, "error", err)
+			return models.RideProgressResponse{}, types.ErrInternalServiceError
+		}
+	}
+
+	now := time.Now()
+	resp := models.RideProgressResponse{
+		RideID:    rideID,
+		Status:    step.to,
+		Timestamp: now,
+		Message:   step.message,
+	}
+
+	if step.to == types.RideStatusCOMPLETED {
+		if resp.FinalFare, err = svc.finalFare(ctx, ride, now); err != nil {
+			log.Error(ctx, action.RideProgress, "error when calculating final fare", "ride_id", rideID, "error", err)
+			return models.RideProgressResponse{}, types.ErrInternalServiceError
+		}
+	}
+
+	data, err := json.Marshal(models.RideStatusEvent{
+		RideID:        rideID,
+		Status:        step.to,
+		Timestamp:     now,
+		DriverID:      driverID,
+		CorrelationID: logger.GetRequestID(ctx),
+	})
+	if err != nil {
+		log.Error(ctx, action.RideProgress, "error marshalling ride status", "error", err)
+		return models.RideProgressResponse{}, types.ErrInternalServiceError
+	}
+
+	fn := func(ctx context.Context) error {
+		if err := svc.repo.ride.UpdateRide(ctx, rideID, ride.Status, step.to, "", &now); err != nil {
+			log.Error(ctx, action.RideProgress, "error updating ride", "error", err)
+			return err
+		}
+
+		if err := svc.repo.event.Insert(ctx, rideID, types.RideEventForStatus(step.to), models.RideEventData{
+			OldStatus:     ride.Status,
+			NewStatus:     step.to,
+			DriverID:      driverID,
+			Timestamp:     now,
+			CorrelationID: logger.GetRequestID(ctx),
+		}); err != nil {
+			log.Error(ctx, action.RideProgress, "error saving ride event", "error", err)
+			return err
+		}
+
+		if step.to == types.RideStatusCOMPLETED {
+			if err := svc.repo.ride.SetFinalFare(ctx, rideID, resp.FinalFare); err != nil {
+				log.Error(ctx, action.RideProgress, "error saving final fare", "error", err)
+				return err
+			}
+
+			if err := svc.repo.driver.CreditRide(ctx, driverID, resp.FinalFare); err != nil {
+				log.Error(ctx, action.RideProgress, "error crediting driver earnings", "error", err)
+				return err
+			}
+
+			if resp.FinalFare != ride.EstimatedFare {
+				if err := svc.repo.event.Insert(ctx, rideID, types.RideEventFareAdjusted, models.RideEventData{
+					DriverID:      driverID,
+					EstimatedFare: ride.EstimatedFare,
+					FinalFare:     resp.FinalFare,
+					Timestamp:     now,
+					CorrelationID: logger.GetRequestID(ctx),
+				}); err != nil {
+					log.Error(ctx, action.RideProgress, "error saving fare event", "error", err)
+					return err
+				}
+			}
+		}
+
+		if err := svc.repo.driver.UpdateStatus(ctx, driverID, step.driverStatus); err != nil {
+			log.Error(ctx, action.RideProgress, "error updating driver status", "error", err)
+			return err
+		}
+
+		if err := svc.repo.outbox.Insert(ctx, models.OutboxMessage{
+			Exchange:      exchangeName,
+			RoutingKey:    fmt.Sprintf("ride.status.%s", step.to),
+			Payload:       data,
+			CorrelationID: logger.GetRequestID(ctx),
+		}); err != nil {
+			log.Error(ctx, action.RideProgress, "error saving ride status to outbox", "error", err)
+			return err
+		}
+		return nil
+	}
+
+	if err = svc.txm.Do(ctx, fn); err != nil {
+		if errors.Is(err, types.ErrRideStatusConflict) {
+			return models.RideProgressResponse{}, types.ErrRideStatusConflict
+		}
+		return models.RideProgressResponse{}, types.ErrInternalServiceError
+	}
+
+	log.Info(ctx, action.RideProgress, "ride status updated", "ride_id", rideID, "status", step.to)
+	return resp, nil
+}
+
+// finalFare считает стоимость по фактическому маршруту из location_history, времени в пути
+// и платному ожиданию на подаче; без точек маршрута берётся прямое расстояние через все остановки
+func (svc *DalService) finalFare(ctx context.Context, ride models.Ride, completedAt time.Time) (float64, error) {
+	log := svc.log.Func("DalService.finalFare")
+
+	points, err := svc.repo.location.ListByRide(ctx, ride.ID, ride.StartedAt, completedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	trip := models.Trip{DistanceKm: calculator.RouteDistance(points)}
+	if trip.DistanceKm == 0 {
+		log.Warn(ctx, action.RideProgress, "no usable route points, using straight-line distance", "ride_id", ride.ID, "points", len(points))
+
+		pickup, err := svc.repo.cord.GetCoordinate(ctx, ride.PickupCoordinateId)
+		if err != nil {
+			return 0, err
+		}
+		destination, err := svc.repo.cord.GetCoordinate(ctx, ride.DestinationCoordinateId)
+		if err != nil {
+			return 0, err
+		}
+		stops, err := svc.repo.stop.ListByRide(ctx, ride.ID)
+		if err != nil {
+			return 0, err
+		}
+
+		from := models.Location{Lat: pickup.Latitude, Lng: pickup.Longitude}
+		for _, s := range stops {
+			trip.DistanceKm += calculator.Distance(from.Lat, from.Lng, s.Location.Lat, s.Location.Lng)
+			from = s.Location
+		}
+		trip.DistanceKm += calculator.Distance(from.Lat, from.Lng, destination.Latitude, destination.Longitude)
+	}
+
+	trip.DurationMinutes = calculator.Duration(trip.DistanceKm)
+	if !ride.StartedAt.IsZero() {
+		trip.DurationMinutes = int(math.Ceil(completedAt.Sub(ride.StartedAt).Minutes()))
+	}
+	if !ride.ArrivedAt.IsZero() && ride.StartedAt.After(ride.ArrivedAt) {
+		trip.WaitingMinutes = int(ride.StartedAt.Sub(ride.ArrivedAt).Minutes())
+	}
+
+	// итог считается по той же версии тарифа и тому же surge, что и оценка при заказе
+	var fare models.Fare
+	if ride.TariffID != "" {
+		fare, err = svc.calc.FareByTariff(ctx, ride.TariffID, trip, ride.SurgeMultiplier)
+	} else {
+		fare, err = svc.calc.Fare(ctx, ride.VehicleType, ride.RequestedAt, trip, ride.SurgeMultiplier)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	log.Info(ctx, action.RideProgress, "final fare calculated", "ride_id", ride.ID,
+		"distance_km", trip.DistanceKm, "duration_min", trip.DurationMinutes, "waiting_min", trip.WaitingMinutes, "fare", fare.Amount)
+	return fare.Amount, nil
+}
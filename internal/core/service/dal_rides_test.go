@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+)
+
+type stubStopRepository struct {
+	stops []models.RideStop
+	err   error
+}
+
+func (r *stubStopRepository) Insert(context.Context, string, int, string) error { return nil }
+
+func (r *stubStopRepository) ListByRide(context.Context, string) ([]models.RideStop, error) {
+	return r.stops, r.err
+}
+
+func (r *stubStopRepository) MarkReached(context.Context, string, int, time.Time) error { return nil }
+
+func TestCheckStopsReached(t *testing.T) {
+	reached := time.Now()
+	listErr := errors.New("db down")
+
+	tests := []struct {
+		name    string
+		stops   []models.RideStop
+		err     error
+		wantErr error
+	}{
+		{name: "no stops"},
+		{name: "all reached", stops: []models.RideStop{{Seq: 1, ReachedAt: &reached}, {Seq: 2, ReachedAt: &reached}}},
+		{name: "last unreached", stops: []models.RideStop{{Seq: 1, ReachedAt: &reached}, {Seq: 2}}, wantErr: types.ErrStopsNotReached},
+		{name: "none reached", stops: []models.RideStop{{Seq: 1}, {Seq: 2}}, wantErr: types.ErrStopsNotReached},
+		{name: "repository error", err: listErr, wantErr: listErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &DalService{repo: dalRepository{stop: &stubStopRepository{stops: tt.stops, err: tt.err}}}
+
+			err := svc.checkStopsReached(context.Background(), "ride-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkStopsReached() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,33 +2,63 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"ride-hail/internal/core/domain/action"
 	"ride-hail/internal/core/domain/models"
 	"ride-hail/internal/core/domain/types"
 	"ride-hail/internal/core/ports"
+	"ride-hail/internal/core/service/calculator"
 	"ride-hail/pkg/logger"
 	"ride-hail/pkg/txm"
 	"time"
 )
 
 type DalService struct {
-	log  *logger.Logger
-	txm  txm.Manager
-	repo dalRepository
+	log      *logger.Logger
+	txm      txm.Manager
+	repo     dalRepository
+	producer ports.RideProducer
+	calc     *calculator.Calculator
+	limiter  *rateLimiter
+	notifier ports.DriverNotifier
+	releases ports.DriverReleaseSubscriber
 }
 
 type dalRepository struct {
-	driver ports.DriversRepository
+	driver   ports.DriversRepository
+	cord     ports.CoordinatesRepository
+	ride     ports.RideRepository
+	stop     ports.RideStopRepository
+	location ports.LocationRepository
+	event    ports.RideEventRepository
+	outbox   ports.OutboxRepository
 }
 
-func NewDalService(log *logger.Logger, txm txm.Manager, driver ports.DriversRepository) *DalService {
+const (
+	locationExchangeName = "location_fanout"
+	locationRoutingKey   = "location"
+	locationMinInterval  = 2 * time.Second
+)
+
+func NewDalService(log *logger.Logger, txm txm.Manager, driver ports.DriversRepository, cord ports.CoordinatesRepository, ride ports.RideRepository, stop ports.RideStopRepository, location ports.LocationRepository, event ports.RideEventRepository, outbox ports.OutboxRepository, producer ports.RideProducer, calc *calculator.Calculator, notifier ports.DriverNotifier, releases ports.DriverReleaseSubscriber) *DalService {
 	return &DalService{
-		log: log,
-		txm: txm,
+		log:      log,
+		txm:      txm,
+		producer: producer,
+		calc:     calc,
+		notifier: notifier,
+		releases: releases,
+		limiter:  newRateLimiter(locationMinInterval),
 		repo: dalRepository{
-			driver: driver,
+			driver:   driver,
+			cord:     cord,
+			ride:     ride,
+			stop:     stop,
+			location: location,
+			event:    event,
+			outbox:   outbox,
 		},
 	}
 }
@@ -39,10 +69,13 @@ func (svc *DalService) CreateNewDriver(ctx context.Context, newDriver models.Dri
 	if _, err := svc.repo.driver.Get(ctx, newDriver.ID); err == nil {
 		log.Warn(ctx, action.Registration, "driver already exists")
 		return types.ErrDriverExists
+	} else if !errors.Is(err, types.ErrDriverNotFound) {
+		log.Error(ctx, action.Registration, "error when getting data from the database", "error", err)
+		return types.ErrInternalServiceError
 	}
 
 	if err := svc.repo.driver.Insert(ctx, newDriver); err != nil {
-		log.Error(ctx, action.Registration, "error when saving data in the database", err)
+		log.Error(ctx, action.Registration, "error when saving data in the database", "error", err)
 		return types.ErrInternalServiceError
 	}
 
@@ -54,60 +87,83 @@ func (svc *DalService) StatusOnline(ctx context.Context, id string, loc models.P
 
 	driver, err := svc.repo.driver.Get(ctx, id)
 	if err != nil {
-		log.Error(ctx, action.UpdateStatus, "error when getting data from the database", err)
+		if errors.Is(err, types.ErrDriverNotFound) {
+			log.Warn(ctx, action.UpdateStatus, "driver not found")
+			return "", types.ErrDriverNotFound
+		}
+		log.Error(ctx, action.UpdateStatus, "error when getting data from the database", "error", err)
 		return "", types.ErrInternalServiceError
 	} else if driver.Status != types.DriverStatusOffline {
 		log.Warn(ctx, action.UpdateStatus, "driver is not offline")
 		return "", types.ErrDriverOnline
 	}
 
-	if session, err := svc.repo.driver.GetLastActiveSession(ctx, driver.ID); err == nil {
+	// сессия, оставшаяся открытой после сбоя, закрывается перед началом новой
+	staleSessionID := ""
+	if session, err := svc.repo.driver.GetLastActiveSession(ctx, driver.ID); err != nil && !errors.Is(err, types.ErrSessionNotFound) {
 		log.Error(ctx, action.UpdateStatus, "failed get last active session", "error", err)
 		return "", types.ErrInternalServiceError
-	} else if !session.EndedAt.IsZero() {
-		log.Warn(ctx, action.UpdateStatus, "the last session didn't end", "error", err)
-		if err = svc.repo.driver.UpdateStatus(ctx, driver.ID, types.DriverStatusOffline); err != nil {
-			log.Error(ctx, action.UpdateStatus, "error when saving data in the database", err)
-		}
-		return "", types.ErrInternalServiceError
+	} else if err == nil && session.EndedAt.IsZero() {
+		log.Warn(ctx, action.UpdateStatus, "the last session didn't end", "session_id", session.ID)
+		staleSessionID = session.ID
 	}
 
 	// проверка времени техосмотра
 	if inspectionDate, err := time.Parse(time.DateOnly, driver.VehicleAttrs.InspectionDate); err != nil {
-		log.Error(ctx, action.UpdateStatus, "error when parsing inspection date", err)
+		log.Error(ctx, action.UpdateStatus, "error when parsing inspection date", "error", err)
 		return "", types.ErrInternalServiceError
 	} else if isInspectionExpired(inspectionDate) {
 		log.Warn(ctx, action.UpdateStatus, "inspection date is expired")
-		return "", fmt.Errorf("inspection date is expired")
+		return "", fmt.Errorf("%w: inspection date", types.ErrDriverDocumentsExpired)
 	}
 
 	// срок действий страховки
 	if insuranceExpiry, err := time.Parse(time.DateOnly, driver.VehicleAttrs.InsuranceExpiry); err != nil {
-		log.Error(ctx, action.UpdateStatus, "error when parsing insurance expiry", err)
+		log.Error(ctx, action.UpdateStatus, "error when parsing insurance expiry", "error", err)
 		return "", types.ErrInternalServiceError
-	} else if err = validateInsurance(insuranceExpiry); err != nil {
-		log.Error(ctx, action.UpdateStatus, "the insurance period has expired")
+	} else if err = validateExpiry(insuranceExpiry, "insurance"); err != nil {
+		log.Warn(ctx, action.UpdateStatus, "the insurance period has expired")
 		return "", err
 	}
 
 	// срок действий лицензии на такси
 	if taxiLicenseExpiry, err := time.Parse(time.DateOnly, driver.VehicleAttrs.TaxiLicenseExpiry); err != nil {
-		log.Error(ctx, action.UpdateStatus, "error when parsing taxi license expiry", err)
-		return "", fmt.Errorf("error when parsing taxi license expiry: %w", err)
-	} else if err = validateInsurance(taxiLicenseExpiry); err != nil {
-		log.Error(ctx, action.UpdateStatus, "the insurance period has expired")
+		log.Error(ctx, action.UpdateStatus, "error when parsing taxi license expiry", "error", err)
+		return "", types.ErrInternalServiceError
+	} else if err = validateExpiry(taxiLicenseExpiry, "taxi license"); err != nil {
+		log.Warn(ctx, action.UpdateStatus, "the taxi license has expired")
 		return "", err
 	}
 
 	var sessionId string
 	fn := func(ctx context.Context) error {
+		if staleSessionID != "" {
+			if err := svc.repo.driver.CloseSession(ctx, staleSessionID); err != nil {
+				log.Error(ctx, action.UpdateStatus, "error when closing stale session", "error", err)
+				return err
+			}
+		}
 		if sessionId, err = svc.repo.driver.InsertSession(ctx, id); err != nil {
-			log.Error(ctx, action.UpdateStatus, "error when saving data in the database", err)
-			return types.ErrInternalServiceError
+			log.Error(ctx, action.UpdateStatus, "error when saving data in the database", "error", err)
+			return err
+		}
+		if err := svc.repo.cord.ResetCurrentCoordinates(ctx, driver.ID, types.EntityRoleDriver); err != nil {
+			log.Error(ctx, action.UpdateStatus, "error when resetting driver coordinates", "error", err)
+			return err
+		}
+		if _, err := svc.repo.cord.CreateNewCoordinate(ctx, models.Coordinate{
+			EntityID:   driver.ID,
+			EntityType: types.EntityRoleDriver,
+			Latitude:   loc.Latitude,
+			Longitude:  loc.Longitude,
+			IsCurrent:  true,
+		}); err != nil {
+			log.Error(ctx, action.UpdateStatus, "error when saving driver coordinate", "error", err)
+			return err
 		}
 		if err := svc.repo.driver.UpdateStatus(ctx, driver.ID, types.DriverStatusAvailable); err != nil {
-			log.Error(ctx, action.UpdateStatus, "error when saving data in the database", err)
-			return types.ErrInternalServiceError
+			log.Error(ctx, action.UpdateStatus, "error when saving data in the database", "error", err)
+			return err
 		}
 		return nil
 	}
@@ -122,9 +178,12 @@ func (svc *DalService) StatusOnline(ctx context.Context, id string, loc models.P
 func (svc *DalService) StatusClose(ctx context.Context, id string) (*models.DriverInfoClosed, error) {
 	log := svc.log.Func("DalService.StatusClose")
 	driver, err := svc.repo.driver.Get(ctx, id)
-
 	if err != nil {
-		log.Error(ctx, action.UpdateStatus, "error when getting data from the database", err)
+		if errors.Is(err, types.ErrDriverNotFound) {
+			log.Warn(ctx, action.UpdateStatus, "driver not found")
+			return nil, types.ErrDriverNotFound
+		}
+		log.Error(ctx, action.UpdateStatus, "error when getting data from the database", "error", err)
 		return nil, types.ErrInternalServiceError
 	}
 
@@ -135,21 +194,30 @@ func (svc *DalService) StatusClose(ctx context.Context, id string) (*models.Driv
 
 	driverSession, err := svc.repo.driver.GetLastActiveSession(ctx, driver.ID)
 	if err != nil {
-		log.Error(ctx, action.UpdateStatus, "error when getting last active session", err)
+		if errors.Is(err, types.ErrSessionNotFound) {
+			log.Warn(ctx, action.UpdateStatus, "the driver has no sessions")
+			return nil, types.ErrDriverStatusNotAllow
+		}
+		log.Error(ctx, action.UpdateStatus, "error when getting last active session", "error", err)
 		return nil, types.ErrInternalServiceError
 	} else if !driverSession.EndedAt.IsZero() {
-		log.Warn(ctx, action.UpdateStatus, "the driver session has not ended")
+		log.Warn(ctx, action.UpdateStatus, "the driver session has already ended")
 		return nil, types.ErrDriverStatusNotAllow
 	}
 
 	fn := func(ctx context.Context) error {
 		if err := svc.repo.driver.CloseSession(ctx, driverSession.ID); err != nil {
-			log.Error(ctx, action.UpdateStatus, "error when closing session", err)
+			log.Error(ctx, action.UpdateStatus, "error when closing session", "error", err)
+			return err
+		}
+
+		if err := svc.repo.cord.ResetCurrentCoordinates(ctx, driver.ID, types.EntityRoleDriver); err != nil {
+			log.Error(ctx, action.UpdateStatus, "error when resetting driver coordinates", "error", err)
 			return err
 		}
 
 		if err := svc.repo.driver.UpdateStatus(ctx, driver.ID, types.DriverStatusOffline); err != nil {
-			log.Error(ctx, action.UpdateStatus, "error when saving data in the database", err)
+			log.Error(ctx, action.UpdateStatus, "error when saving data in the database", "error", err)
 			return err
 		}
 		return nil
@@ -171,13 +239,90 @@ func (svc *DalService) StatusClose(ctx context.Context, id string) (*models.Driv
 	}, nil
 }
 
+func (svc *DalService) UpdateLocation(ctx context.Context, driverID string, loc models.LocationUpdate) (string, error) {
+	log := svc.log.Func("DalService.UpdateLocation")
+
+	if !svc.limiter.Allow(driverID) {
+		log.Debug(ctx, action.Location, "location update rate limited", "driver_id", driverID)
+		return "", types.ErrLocationRateLimited
+	}
+
+	driver, err := svc.repo.driver.Get(ctx, driverID)
+	if err != nil {
+		if errors.Is(err, types.ErrDriverNotFound) {
+			log.Warn(ctx, action.Location, "driver not found")
+			return "", types.ErrDriverNotFound
+		}
+		log.Error(ctx, action.Location, "error when getting data from the database", "error", err)
+		return "", types.ErrInternalServiceError
+	} else if driver.Status == types.DriverStatusOffline {
+		log.Warn(ctx, action.Location, "driver is offline")
+		return "", types.ErrDriverStatusNotAllow
+	}
+
+	rideID, err := svc.repo.ride.GetActiveRideID(ctx, driverID)
+	if err != nil && !errors.Is(err, types.ErrRideNotFound) {
+		log.Error(ctx, action.Location, "error when getting active ride", "error", err)
+		return "", types.ErrInternalServiceError
+	}
+
+	now := time.Now()
+	var coordinateID string
+	fn := func(ctx context.Context) error {
+		if coordinateID, err = svc.repo.cord.UpdateCurrentCoordinate(ctx, driverID, types.EntityRoleDriver, loc.Latitude, loc.Longitude); err != nil {
+			log.Error(ctx, action.Location, "error when updating driver coordinate", "error", err)
+			return err
+		}
+
+		if err := svc.repo.location.Insert(ctx, models.LocationHistory{
+			CoordinateID:   coordinateID,
+			DriverID:       driverID,
+			Latitude:       loc.Latitude,
+			Longitude:      loc.Longitude,
+			AccuracyMeters: loc.AccuracyMeters,
+			SpeedKmh:       loc.SpeedKmh,
+			HeadingDegrees: loc.HeadingDegrees,
+			RecordedAt:     now,
+			RideID:         rideID,
+		}); err != nil {
+			log.Error(ctx, action.Location, "error when saving location history", "error", err)
+			return err
+		}
+		return nil
+	}
+
+	if err = svc.txm.Do(ctx, fn); err != nil {
+		return "", types.ErrInternalServiceError
+	}
+
+	data, err := json.Marshal(models.DriverLocationUpdate{
+		DriverID:  driverID,
+		RideID:    rideID,
+		Location:  models.LocationDriver{Lat: loc.Latitude, Lng: loc.Longitude},
+		SpeedKmh:  loc.SpeedKmh,
+		Heading:   loc.HeadingDegrees,
+		Timestamp: now,
+	})
+	if err != nil {
+		log.Error(ctx, action.Location, "failed to marshal driver location", "error", err)
+		return "", types.ErrInternalServiceError
+	}
+
+	if err = svc.producer.Producer(ctx, locationExchangeName, locationRoutingKey, data); err != nil {
+		log.Error(ctx, action.Location, "failed to publish driver location", "error", err)
+		return "", types.ErrInternalServiceError
+	}
+
+	return coordinateID, nil
+}
+
 func isInspectionExpired(inspectionDate time.Time) bool {
 	return time.Now().After(inspectionDate.AddDate(0, 6, 0))
 }
 
-func validateInsurance(expiry time.Time) error {
+func validateExpiry(expiry time.Time, document string) error {
 	if time.Now().After(expiry) {
-		return errors.New("the insurance period has expired")
+		return fmt.Errorf("%w: %s", types.ErrDriverDocumentsExpired, document)
 	}
 	return nil
 }
@@ -0,0 +1,404 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"ride-hail/config"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/internal/core/ports"
+	"ride-hail/internal/core/service/calculator"
+	"ride-hail/internal/core/service/routing"
+	"ride-hail/pkg/logger"
+	"ride-hail/pkg/txm"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	driverExchangeName   = "driver_topic"
+	defaultMatchTimeout  = 30 * time.Second
+	offerTimeout         = 15 * time.Second
+	noCandidatesInterval = 3 * time.Second
+	candidatesLimit      = 10
+)
+
+type MatchingService struct {
+	log      *logger.Logger
+	txm      txm.Manager
+	repo     matchingRepository
+	notifier ports.DriverNotifier
+	consumer ports.RideRequestSubscriber
+	routing  *routing.Router
+	rating   config.Rating
+
+	mu     sync.Mutex
+	offers map[string]*pendingOffer // driverID -> предложение, ожидающее ответа
+	rides  map[string]struct{}      // поездки, для которых идёт подбор
+}
+
+type matchingRepository struct {
+	driver ports.DriversRepository
+	ride   ports.RideRepository
+	outbox ports.OutboxRepository
+}
+
+type pendingOffer struct {
+	id     string // offer_id, который водитель должен вернуть в ответе
+	rideID string
+	resp   chan bool
+}
+
+func NewMatchingService(log *logger.Logger, txm txm.Manager, driverRepo ports.DriversRepository, rideRepo ports.RideRepository, outboxRepo ports.OutboxRepository, notifier ports.DriverNotifier, consumer ports.RideRequestSubscriber, router *routing.Router, rating config.Rating) *MatchingService {
+	return &MatchingService{
+		log: log,
+		txm: txm,
+		repo: matchingRepository{
+			driver: driverRepo,
+			ride:   rideRepo,
+			outbox: outboxRepo,
+		},
+		notifier: notifier,
+		consumer: consumer,
+		routing:  router,
+		rating:   rating,
+		offers:   make(map[string]*pendingOffer),
+		rides:    make(map[string]struct{}),
+	}
+}
+
+func (svc *MatchingService) StartService(ctx context.Context) {
+	log := svc.log.Func("MatchingService.StartService")
+
+	go runWithRetry(ctx, svc.log, svc.rideRequests, "MatchingService.rideRequests")
+
+	log.Debug(ctx, action.MatchRide, "MatchingService started")
+	<-ctx.Done()
+	log.Debug(ctx, action.MatchRide, "MatchingService stopping")
+}
+
+func (svc *MatchingService) rideRequests(ctx context.Context) error {
+	log := svc.log.Func("MatchingService.rideRequests")
+
+	ch, err := svc.consumer.Subscribe(ctx)
+	if err != nil {
+		log.Error(ctx, action.MatchRide, "failed to subscribe to ride requests", "error", err)
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug(ctx, action.MatchRide, "ride requests stopped")
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				log.Debug(ctx, action.MatchRide, "ride requests channel closed")
+				return fmt.Errorf("ride requests channel closed")
+			}
+			// запрос подтверждается только после подбора: обработчик консьюмера ждёт Done,
+			// поэтому одновременно подбирается не больше поездок, чем Workers у консьюмера
+			go func() { msg.Done(svc.matchRide(ctx, msg.Event)) }()
+		}
+	}
+}
+
+// matchRide предлагает поездку ближайшим свободным водителям по одному,
+// пока кто-то не примет предложение или не истечёт TimeoutSeconds. Ошибка возвращает запрос в очередь;
+// если водителя так и не нашлось, запрос подтверждается — дальше поездкой займётся redispatch
+func (svc *MatchingService) matchRide(ctx context.Context, req models.RideRequestRideType) error {
+	log := svc.log.Func("MatchingService.matchRide")
+	ctx = logger.WithRequestID(ctx, req.CorrelationID)
+
+	if !svc.lockRide(req.RideID) {
+		log.Debug(ctx, action.MatchRide, "ride is already being matched", "ride_id", req.RideID)
+		return nil
+	}
+	defer svc.unlockRide(req.RideID)
+
+	timeout := time.Duration(req.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultMatchTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	declined := make(map[string]struct{})
+
+	for time.Now().Before(deadline) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if ride, err := svc.repo.ride.GetRide(ctx, req.RideID); err != nil {
+			log.Error(ctx, action.MatchRide, "failed to get ride", "ride_id", req.RideID, "error", err)
+			return skipIfNotFound(err)
+		} else if ride.Status != types.RideStatusREQUESTED {
+			log.Info(ctx, action.MatchRide, "ride is no longer requested", "ride_id", req.RideID, "status", ride.Status)
+			return nil
+		}
+
+		candidate, found, err := svc.nextCandidate(ctx, req, declined)
+		if err != nil {
+			log.Error(ctx, action.MatchRide, "failed to find drivers", "ride_id", req.RideID, "error", err)
+			return err
+		}
+		if !found {
+			if !sleepUntil(ctx, noCandidatesInterval, deadline) {
+				break
+			}
+			continue
+		}
+
+		wait := min(offerTimeout, time.Until(deadline))
+		accepted := svc.offerRide(ctx, req, candidate, wait)
+		if !accepted {
+			svc.releaseDriver(candidate.DriverID)
+			declined[candidate.DriverID] = struct{}{}
+			continue
+		}
+
+		err = svc.assignDriver(ctx, req, candidate)
+		svc.releaseDriver(candidate.DriverID)
+		if err != nil {
+			log.Warn(ctx, action.MatchRide, "failed to assign driver", "ride_id", req.RideID, "driver_id", candidate.DriverID, "error", err)
+			declined[candidate.DriverID] = struct{}{}
+			continue
+		}
+
+		log.Info(ctx, action.MatchRide, "driver matched", "ride_id", req.RideID, "driver_id", candidate.DriverID)
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	log.Warn(ctx, action.MatchRide, "no driver accepted the ride in time", "ride_id", req.RideID)
+	return nil
+}
+
+// nextCandidate выбирает лучшего водителя, который ещё не отказался и не занят другим предложением,
+// и резервирует его за поездкой
+func (svc *MatchingService) nextCandidate(ctx context.Context, req models.RideRequestRideType, declined map[string]struct{}) (models.DriverCandidate, bool, error) {
+	candidates, err := svc.repo.driver.FindAvailableNearby(ctx, models.DriverSearch{
+		Latitude:    req.PickupLocation.Lat,
+		Longitude:   req.PickupLocation.Lng,
+		VehicleType: req.RideType,
+		RadiusKm:    req.MaxDistanceKm,
+		Limit:       candidatesLimit,
+	})
+	if err != nil {
+		return models.DriverCandidate{}, false, err
+	}
+
+	candidates = slices.DeleteFunc(candidates, func(c models.DriverCandidate) bool {
+		_, ok := declined[c.DriverID]
+		return ok
+	})
+	svc.rankByArrival(ctx, req.PickupLocation, candidates)
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	for _, c := range candidates {
+		if _, busy := svc.offers[c.DriverID]; busy {
+			continue
+		}
+		svc.offers[c.DriverID] = &pendingOffer{id: newOfferID(), rideID: req.RideID, resp: make(chan bool, 1)}
+		return c, true, nil
+	}
+
+	return models.DriverCandidate{}, false, nil
+}
+
+// rankByArrival заменяет у кандидатов расстояние по прямой на расстояние по дорогам, считает время подачи
+// и сортирует по нему с поправкой на рейтинг: ближайший по прямой водитель может оказаться за рекой
+func (svc *MatchingService) rankByArrival(ctx context.Context, pickup models.Location, candidates []models.DriverCandidate) {
+	to := models.Position{Latitude: pickup.Lat, Longitude: pickup.Lng}
+
+	var wg sync.WaitGroup
+	for i := range candidates {
+		wg.Add(1)
+		go func(c *models.DriverCandidate) {
+			defer wg.Done()
+			route := svc.routing.Route(ctx, models.Position{Latitude: c.Location.Lat, Longitude: c.Location.Lng}, to)
+			c.DistanceKm = route.DistanceKm
+			c.EstimatedArrivalMinutes = route.DurationMinutes
+		}(&candidates[i])
+	}
+	wg.Wait()
+
+	slices.SortStableFunc(candidates, func(a, b models.DriverCandidate) int {
+		if c := cmp.Compare(svc.rankScore(a), svc.rankScore(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.DistanceKm, b.DistanceKm)
+	})
+}
+
+// rankScore — время подачи, к которому каждая недостающая до 5 звезда добавляет EtaMinutesPerStar минут
+func (svc *MatchingService) rankScore(c models.DriverCandidate) float64 {
+	return float64(c.EstimatedArrivalMinutes) + (5-c.Rating)*svc.rating.EtaMinutesPerStar
+}
+
+func (svc *MatchingService) offerRide(ctx context.Context, req models.RideRequestRideType, candidate models.DriverCandidate, wait time.Duration) bool {
+	log := svc.log.Func("MatchingService.offerRide")
+
+	svc.mu.Lock()
+	offer, ok := svc.offers[candidate.DriverID]
+	svc.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	trip := calculator.TripOf(svc.routing.Legs(ctx, routePoints(req.PickupLocation, req.Stops, req.DestinationLocation)...))
+	if err := svc.notifier.SendRideOffer(ctx, candidate.DriverID, models.RideOffer{
+		OfferID:                      offer.id,
+		RideID:                       req.RideID,
+		RideNumber:                   req.RideNumber,
+		PickupLocation:               req.PickupLocation,
+		DestinationLocation:          req.DestinationLocation,
+		Stops:                        req.Stops,
+		EstimatedFare:                req.EstimatedFare,
+		DistanceToPickupKm:           candidate.DistanceKm,
+		EstimatedRideDurationMinutes: trip.DurationMinutes,
+		ExpiresAt:                    time.Now().Add(wait),
+	}); err != nil {
+		log.Warn(ctx, action.MatchRide, "failed to send ride offer", "driver_id", candidate.DriverID, "error", err)
+		return false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		log.Debug(ctx, action.MatchRide, "ride offer expired", "driver_id", candidate.DriverID, "ride_id", req.RideID)
+		return false
+	case accepted := <-offer.resp:
+		return accepted
+	}
+}
+
+func (svc *MatchingService) assignDriver(ctx context.Context, req models.RideRequestRideType, candidate models.DriverCandidate) error {
+	log := svc.log.Func("MatchingService.assignDriver")
+
+	event := models.DriverResponseEvent{
+		RideID:                  req.RideID,
+		DriverID:                candidate.DriverID,
+		Accepted:                true,
+		EstimatedArrivalMinutes: candidate.EstimatedArrivalMinutes,
+		CorrelationID:           req.CorrelationID,
+	}
+	event.DriverLocation.Lat = candidate.Location.Lat
+	event.DriverLocation.Lng = candidate.Location.Lng
+	event.DriverInfo.Name = candidate.Name
+	event.DriverInfo.Rating = candidate.Rating
+	event.DriverInfo.Vehicle.Make = candidate.VehicleAttrs.Make
+	event.DriverInfo.Vehicle.Model = candidate.VehicleAttrs.Model
+	event.DriverInfo.Vehicle.Color = candidate.VehicleAttrs.Color
+	event.DriverInfo.Vehicle.Plate = candidate.VehicleAttrs.LicensePlate
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Error(ctx, action.MatchRide, "failed to marshal driver response", "error", err)
+		return err
+	}
+
+	fn := func(ctx context.Context) error {
+		if err := svc.repo.driver.UpdateStatusFrom(ctx, candidate.DriverID, types.DriverStatusAvailable, types.DriverStatusEnRoute); err != nil {
+			return err
+		}
+
+		if err := svc.repo.outbox.Insert(ctx, models.OutboxMessage{
+			Exchange:      driverExchangeName,
+			RoutingKey:    fmt.Sprintf("driver.response.%s", req.RideID),
+			Payload:       data,
+			CorrelationID: req.CorrelationID,
+		}); err != nil {
+			log.Error(ctx, action.MatchRide, "failed to save driver response to outbox", "error", err)
+			return err
+		}
+		return nil
+	}
+
+	return svc.txm.Do(ctx, fn)
+}
+
+// HandleOfferResponse передаёт ответ водителя ожидающему предложению; ответ на прошлое предложение
+// по той же поездке отбрасывается по offer_id
+func (svc *MatchingService) HandleOfferResponse(ctx context.Context, driverID, offerID, rideID string, accepted bool) error {
+	log := svc.log.Func("MatchingService.HandleOfferResponse")
+
+	svc.mu.Lock()
+	offer, ok := svc.offers[driverID]
+	svc.mu.Unlock()
+
+	if !ok || offer.id != offerID || offer.rideID != rideID {
+		log.Warn(ctx, action.MatchRide, "no pending offer for driver", "driver_id", driverID, "offer_id", offerID, "ride_id", rideID)
+		return types.ErrOfferNotFound
+	}
+
+	select {
+	case offer.resp <- accepted:
+		return nil
+	default:
+		return types.ErrOfferNotFound
+	}
+}
+
+func (svc *MatchingService) releaseDriver(driverID string) {
+	svc.mu.Lock()
+	delete(svc.offers, driverID)
+	svc.mu.Unlock()
+}
+
+func (svc *MatchingService) lockRide(rideID string) bool {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	if _, ok := svc.rides[rideID]; ok {
+		return false
+	}
+	svc.rides[rideID] = struct{}{}
+	return true
+}
+
+func (svc *MatchingService) unlockRide(rideID string) {
+	svc.mu.Lock()
+	delete(svc.rides, rideID)
+	svc.mu.Unlock()
+}
+
+// sleepUntil ждёт d, но не дольше deadline; возвращает false, если ждать больше нечего
+func sleepUntil(ctx context.Context, d time.Duration, deadline time.Time) bool {
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(min(d, remaining))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func newOfferID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return hex.EncodeToString(fmt.Appendf(nil, "%d", time.Now().UnixNano()))
+	}
+	return hex.EncodeToString(b)
+}
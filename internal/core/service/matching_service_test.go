@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/logger"
+)
+
+func TestHandleOfferResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		driverID  string
+		offerID   string
+		rideID    string
+		wantErr   error
+		wantReply bool
+	}{
+		{name: "reply to pending offer", driverID: "d1", offerID: "o2", rideID: "r1", wantReply: true},
+		{name: "late reply to previous offer for the same ride", driverID: "d1", offerID: "o1", rideID: "r1", wantErr: types.ErrOfferNotFound},
+		{name: "other ride", driverID: "d1", offerID: "o2", rideID: "r2", wantErr: types.ErrOfferNotFound},
+		{name: "no offer for driver", driverID: "d2", offerID: "o2", rideID: "r1", wantErr: types.ErrOfferNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offer := &pendingOffer{id: "o2", rideID: "r1", resp: make(chan bool, 1)}
+			svc := &MatchingService{
+				log:    logger.NewLogger("test", logger.Options{Output: io.Discard}),
+				offers: map[string]*pendingOffer{"d1": offer},
+			}
+
+			err := svc.HandleOfferResponse(context.Background(), tt.driverID, tt.offerID, tt.rideID, true)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("HandleOfferResponse() error = %v, want %v", err, tt.wantErr)
+			}
+
+			select {
+			case <-offer.resp:
+				if !tt.wantReply {
+					t.Error("reply was delivered to the pending offer")
+				}
+			default:
+				if tt.wantReply {
+					t.Error("reply was not delivered to the pending offer")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/ports"
+	"ride-hail/pkg/logger"
+	"ride-hail/pkg/rabbit"
+	"time"
+)
+
+const (
+	outboxPollInterval = 500 * time.Millisecond
+	outboxBatchSize    = 50
+	// outboxLease — на сколько забранная пачка скрыта от других relay; если relay упал, сообщения
+	// вернутся в работу через это время
+	outboxLease = time.Minute
+	// outboxPublishBudget — запас аренды на одну публикацию с ожиданием подтверждения
+	outboxPublishBudget = 10 * time.Second
+	// после стольких nack брокера сообщение паркуется; ошибки соединения повторяются бесконечно
+	outboxMaxRejections = 5
+	outboxBaseBackoff   = time.Second
+	outboxMaxBackoff    = time.Minute
+)
+
+// OutboxRelay публикует в RabbitMQ сообщения, записанные в outbox вместе с бизнес-данными
+type OutboxRelay struct {
+	log      *logger.Logger
+	repo     ports.OutboxRepository
+	producer ports.RideProducer
+}
+
+func NewOutboxRelay(log *logger.Logger, repo ports.OutboxRepository, producer ports.RideProducer) *OutboxRelay {
+	return &OutboxRelay{
+		log:      log,
+		repo:     repo,
+		producer: producer,
+	}
+}
+
+func (r *OutboxRelay) Run(ctx context.Context) {
+	log := r.log.Func("OutboxRelay.Run")
+
+	log.Debug(ctx, action.Outbox, "outbox relay started")
+
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug(ctx, action.Outbox, "outbox relay stopped")
+			return
+		case <-ticker.C:
+			// полная пачка — скорее всего есть ещё, забираем не дожидаясь тика
+			for {
+				n, err := r.relayBatch(ctx)
+				if err != nil {
+					log.Error(ctx, action.Outbox, "failed to relay outbox batch", "error", err)
+					break
+				}
+				if n < outboxBatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// relayBatch забирает пачку в аренду и публикует её вне транзакции, возвращая число отправленных сообщений.
+// Повторная отправка после падения relay возможна, получатель отсеет её по MessageId.
+func (r *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	leaseUntil := time.Now().Add(outboxLease)
+
+	messages, err := r.repo.ClaimPending(ctx, outboxBatchSize, leaseUntil)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, m := range messages {
+		// остаток пачки не успеем отправить до конца аренды, его заберут заново
+		if time.Until(leaseUntil) < outboxPublishBudget || ctx.Err() != nil {
+			break
+		}
+
+		// id записи уходит в MessageId, по нему получатель отличит повторную отправку
+		pubCtx := rabbit.WithMessageID(logger.WithRequestID(ctx, m.CorrelationID), m.ID)
+		pubErr := r.producer.Producer(pubCtx, m.Exchange, m.RoutingKey, m.Payload)
+		if pubErr == nil {
+			if err = r.repo.MarkSent(ctx, m.ID); err != nil {
+				return sent, err
+			}
+			sent++
+			continue
+		}
+
+		// неудачное сообщение не держит остальные: оно уходит на повтор с задержкой,
+		// поэтому его порядок относительно более поздних сообщений не гарантируется
+		if err = r.fail(ctx, m, pubErr); err != nil {
+			return sent, err
+		}
+		if brokerUnavailable(pubErr) {
+			// остальные сообщения пачки упадут так же, они вернутся в работу по окончании аренды
+			break
+		}
+	}
+
+	return sent, nil
+}
+
+// fail паркует сообщение, которое брокер не принимает: маршрута нет или он раз за разом отвечает nack.
+// Ошибки соединения, закрытого канала и таймаута подтверждения откладывают сообщение без счёта:
+// пока брокер лежит, ничего не должно парковаться
+func (r *OutboxRelay) fail(ctx context.Context, m models.OutboxMessage, pubErr error) error {
+	log := r.log.Func("OutboxRelay.fail")
+
+	switch {
+	case errors.Is(pubErr, rabbit.ErrMessageReturned):
+		log.Error(ctx, action.Outbox, "unroutable outbox message parked", "id", m.ID, "routing_key", m.RoutingKey, "error", pubErr)
+		return r.repo.Park(ctx, m.ID, pubErr.Error())
+
+	case errors.Is(pubErr, rabbit.ErrMessageNacked):
+		rejections := m.Rejections + 1
+		if rejections >= outboxMaxRejections {
+			log.Error(ctx, action.Outbox, "outbox message parked", "id", m.ID, "routing_key", m.RoutingKey, "rejections", rejections, "error", pubErr)
+			return r.repo.Park(ctx, m.ID, pubErr.Error())
+		}
+		log.Warn(ctx, action.Outbox, "outbox message rejected by broker", "id", m.ID, "routing_key", m.RoutingKey, "rejections", rejections)
+		return r.repo.MarkRejected(ctx, m.ID, pubErr.Error(), time.Now().Add(outboxBackoff(rejections)))
+
+	default:
+		attempts := m.Attempts + 1
+		log.Warn(ctx, action.Outbox, "failed to publish outbox message", "id", m.ID, "routing_key", m.RoutingKey, "attempts", attempts, "error", pubErr)
+		return r.repo.MarkFailed(ctx, m.ID, pubErr.Error(), time.Now().Add(outboxBackoff(attempts)))
+	}
+}
+
+// brokerUnavailable — ошибка не связана с самим сообщением
+func brokerUnavailable(err error) bool {
+	return !errors.Is(err, rabbit.ErrMessageReturned) && !errors.Is(err, rabbit.ErrMessageNacked)
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	d := outboxBaseBackoff << min(attempts-1, 20)
+	return min(d, outboxMaxBackoff)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/pkg/logger"
+	"ride-hail/pkg/rabbit"
+)
+
+type stubOutboxRepository struct {
+	pending  []models.OutboxMessage
+	sent     []string
+	failed   []string
+	rejected []string
+	parked   []string
+}
+
+func (r *stubOutboxRepository) Insert(context.Context, models.OutboxMessage) error { return nil }
+
+func (r *stubOutboxRepository) ClaimPending(context.Context, int, time.Time) ([]models.OutboxMessage, error) {
+	return r.pending, nil
+}
+
+func (r *stubOutboxRepository) MarkSent(_ context.Context, id string) error {
+	r.sent = append(r.sent, id)
+	return nil
+}
+
+func (r *stubOutboxRepository) MarkFailed(_ context.Context, id, _ string, _ time.Time) error {
+	r.failed = append(r.failed, id)
+	return nil
+}
+
+func (r *stubOutboxRepository) MarkRejected(_ context.Context, id, _ string, _ time.Time) error {
+	r.rejected = append(r.rejected, id)
+	return nil
+}
+
+func (r *stubOutboxRepository) Park(_ context.Context, id, _ string) error {
+	r.parked = append(r.parked, id)
+	return nil
+}
+
+func (r *stubOutboxRepository) ListParked(context.Context, int) ([]models.ParkedMessage, error) {
+	return nil, nil
+}
+
+func (r *stubOutboxRepository) Unpark(context.Context, int) (int, error) { return 0, nil }
+
+// stubProducer отвечает ошибкой из errs по routing key сообщения
+type stubProducer struct {
+	errs      map[string]error
+	published []string
+}
+
+func (p *stubProducer) Producer(_ context.Context, _, routingKey string, _ []byte) error {
+	p.published = append(p.published, routingKey)
+	return p.errs[routingKey]
+}
+
+func TestOutboxRelayBatch(t *testing.T) {
+	connErr := errors.New("error in publishing message: connection is closed")
+
+	tests := []struct {
+		name         string
+		pending      []models.OutboxMessage
+		errs         map[string]error
+		wantSent     []string
+		wantFailed   []string
+		wantRejected []string
+		wantParked   []string
+	}{
+		{
+			name:     "all published",
+			pending:  []models.OutboxMessage{{ID: "1", RoutingKey: "a"}, {ID: "2", RoutingKey: "b"}},
+			wantSent: []string{"1", "2"},
+		},
+		{
+			name:       "unroutable is parked, the rest goes on",
+			pending:    []models.OutboxMessage{{ID: "1", RoutingKey: "a"}, {ID: "2", RoutingKey: "b"}},
+			errs:       map[string]error{"a": rabbit.ErrMessageReturned},
+			wantSent:   []string{"2"},
+			wantParked: []string{"1"},
+		},
+		{
+			name:         "nack is retried until the rejection limit",
+			pending:      []models.OutboxMessage{{ID: "1", RoutingKey: "a"}, {ID: "2", RoutingKey: "b", Rejections: outboxMaxRejections - 1}, {ID: "3", RoutingKey: "c"}},
+			errs:         map[string]error{"a": rabbit.ErrMessageNacked, "b": rabbit.ErrMessageNacked},
+			wantSent:     []string{"3"},
+			wantRejected: []string{"1"},
+			wantParked:   []string{"2"},
+		},
+		{
+			name:       "broker outage is never parked and stops the batch",
+			pending:    []models.OutboxMessage{{ID: "1", RoutingKey: "a"}, {ID: "2", RoutingKey: "b", Attempts: 1000}, {ID: "3", RoutingKey: "c"}},
+			errs:       map[string]error{"b": connErr, "c": connErr},
+			wantSent:   []string{"1"},
+			wantFailed: []string{"2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubOutboxRepository{pending: tt.pending}
+			relay := NewOutboxRelay(logger.NewLogger("test", logger.Options{Output: io.Discard}), repo, &stubProducer{errs: tt.errs})
+
+			sent, err := relay.relayBatch(context.Background())
+			if err != nil {
+				t.Fatalf("relayBatch() error = %v", err)
+			}
+			if sent != len(tt.wantSent) {
+				t.Errorf("relayBatch() = %d, want %d", sent, len(tt.wantSent))
+			}
+
+			check := func(what string, got, want []string) {
+				t.Helper()
+				if !slices.Equal(got, want) {
+					t.Errorf("%s = %v, want %v", what, got, want)
+				}
+			}
+			check("sent", repo.sent, tt.wantSent)
+			check("failed", repo.failed, tt.wantFailed)
+			check("rejected", repo.rejected, tt.wantRejected)
+			check("parked", repo.parked, tt.wantParked)
+		})
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{6, 32 * time.Second},
+		{7, outboxMaxBackoff},
+		{1000, outboxMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := outboxBackoff(tt.attempts); got != tt.want {
+			t.Errorf("outboxBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
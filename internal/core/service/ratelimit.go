@@ -0,0 +1,56 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiterSweepEvery — как часто из карты выбрасываются ключи, которые давно не присылали событий
+const rateLimiterSweepEvery = time.Minute
+
+// rateLimiter пропускает не больше одного события на ключ за interval
+type rateLimiter struct {
+	mu        sync.Mutex
+	interval  time.Duration
+	last      map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newRateLimiter(interval time.Duration) *rateLimiter {
+	return &rateLimiter{
+		interval:  interval,
+		last:      make(map[string]time.Time),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (l *rateLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	if last, ok := l.last[key]; ok && now.Sub(last) < l.interval {
+		return false
+	}
+	l.last[key] = now
+	return true
+}
+
+// sweep удаляет ключи старше interval: такая запись уже ничего не ограничивает,
+// а без чистки карта растёт с каждым водителем, когда-либо вышедшим на линию
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < max(l.interval, rateLimiterSweepEvery) {
+		return
+	}
+	l.lastSweep = now
+
+	for k, last := range l.last {
+		if now.Sub(last) >= l.interval {
+			delete(l.last, k)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter(time.Second)
+	l.now = func() time.Time { return now }
+
+	if !l.Allow("d1") {
+		t.Fatal("first event must pass")
+	}
+	if l.Allow("d1") {
+		t.Fatal("second event within interval must be limited")
+	}
+	if !l.Allow("d2") {
+		t.Fatal("keys are limited independently")
+	}
+
+	now = now.Add(time.Second)
+	if !l.Allow("d1") {
+		t.Fatal("event after interval must pass")
+	}
+}
+
+func TestRateLimiterEvictsIdleKeys(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter(time.Second)
+	l.now = func() time.Time { return now }
+	l.lastSweep = now
+
+	for _, key := range []string{"d1", "d2", "d3"} {
+		l.Allow(key)
+	}
+
+	// d3 остаётся активным, остальные замолкают
+	now = now.Add(rateLimiterSweepEvery - 500*time.Millisecond)
+	l.Allow("d3")
+	if len(l.last) != 3 {
+		t.Fatalf("keys swept before sweep interval: %d left", len(l.last))
+	}
+
+	now = now.Add(600 * time.Millisecond)
+	l.Allow("d4")
+	if _, ok := l.last["d1"]; ok {
+		t.Error("idle key d1 was not evicted")
+	}
+	if _, ok := l.last["d3"]; !ok {
+		t.Error("recently seen key d3 was evicted")
+	}
+	if len(l.last) != 2 {
+		t.Errorf("len = %d, want 2 (d3, d4)", len(l.last))
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/pkg/logger"
+	"time"
+)
+
+func runWithRetry(ctx context.Context, l *logger.Logger, fn func(ctx context.Context) error, name string) {
+	log := l.Func(name)
+	backoff := time.Second
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug(ctx, action.ServiceRide, "stopping service")
+			return
+		default:
+			err := fn(ctx)
+			if err != nil {
+				log.Error(ctx, action.ServiceRide, fmt.Sprintf("%s failed, retrying", name), "error", err)
+				time.Sleep(backoff)
+				if backoff < 30*time.Second {
+					backoff *= 2
+				}
+			} else {
+				backoff = time.Second
+			}
+		}
+	}
+}
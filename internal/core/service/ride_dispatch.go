@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/logger"
+	"time"
+)
+
+const (
+	dispatchPollInterval = 2 * time.Second
+	dispatchBatchSize    = 20
+	// matching может ещё ждать ответа на последнее предложение, даём ему закончить
+	dispatchGrace = 5 * time.Second
+)
+
+// dispatchTimeouts следит за поездками, которым так и не нашёлся водитель:
+// повторно публикует запрос с расширенным радиусом, а после MaxRedispatch попыток отменяет поездку
+func (svc *RideService) dispatchTimeouts(ctx context.Context) {
+	log := svc.log.Func("RideService.dispatchTimeouts")
+
+	ticker := time.NewTicker(dispatchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug(ctx, action.DispatchRide, "dispatch scheduler stopped")
+			return
+		case <-ticker.C:
+			for {
+				n, err := svc.expireDispatches(ctx)
+				if err != nil {
+					log.Error(ctx, action.DispatchRide, "failed to process expired dispatches", "error", err)
+					break
+				}
+				if n < dispatchBatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (svc *RideService) expireDispatches(ctx context.Context) (int, error) {
+	log := svc.log.Func("RideService.expireDispatches")
+
+	timeout := time.Duration(svc.dispatch.TimeoutSeconds)*time.Second + dispatchGrace
+	now := time.Now()
+
+	var n int
+
+	fn := func(ctx context.Context) error {
+		dispatches, err := svc.repo.ride.ListExpiredDispatches(ctx, now.Add(-timeout), dispatchBatchSize)
+		if err != nil {
+			return err
+		}
+		n = len(dispatches)
+
+		for _, d := range dispatches {
+			if d.Attempts < svc.dispatch.MaxRedispatch {
+				if err = svc.redispatchRide(ctx, d, now); err != nil {
+					return fmt.Errorf("failed to redispatch ride %s: %w", d.Ride.ID, err)
+				}
+				log.Info(ctx, action.DispatchRide, "ride request redispatched", "ride_id", d.Ride.ID, "attempt", d.Attempts+1)
+				continue
+			}
+
+			if err = svc.cancelNoDrivers(ctx, d.Ride, now); err != nil {
+				return fmt.Errorf("failed to cancel ride %s: %w", d.Ride.ID, err)
+			}
+			log.Warn(ctx, action.DispatchRide, "ride cancelled, no drivers available", "ride_id", d.Ride.ID)
+		}
+
+		return nil
+	}
+
+	if err := svc.txm.Do(ctx, fn); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// redispatchRide публикует запрос заново, каждая попытка расширяет радиус поиска на RadiusStepKm
+func (svc *RideService) redispatchRide(ctx context.Context, d models.RideDispatch, now time.Time) error {
+	pickup, err := svc.repo.cord.GetCoordinate(ctx, d.Ride.PickupCoordinateId)
+	if err != nil {
+		return err
+	}
+
+	destination, err := svc.repo.cord.GetCoordinate(ctx, d.Ride.DestinationCoordinateId)
+	if err != nil {
+		return err
+	}
+
+	stops, err := svc.stopLocations(ctx, d.Ride.ID)
+	if err != nil {
+		return err
+	}
+
+	if err = svc.repo.ride.MarkRedispatched(ctx, d.Ride.ID); err != nil {
+		return err
+	}
+
+	radius := searchRadiusKm + float64(d.Attempts+1)*svc.dispatch.RadiusStepKm
+
+	if err = svc.repo.event.Insert(ctx, d.Ride.ID, types.RideEventRedispatched, models.RideEventData{
+		PassengerID: d.Ride.PassengerID,
+		RadiusKm:    radius,
+		Timestamp:   now,
+	}); err != nil {
+		return err
+	}
+
+	return svc.publishRideRequest(ctx, models.RideRequestRideType{
+		RideID:              d.Ride.ID,
+		RideNumber:          d.Ride.RideNumber,
+		PickupLocation:      models.Location{Lat: pickup.Latitude, Lng: pickup.Longitude, Address: pickup.Address},
+		DestinationLocation: models.Location{Lat: destination.Latitude, Lng: destination.Longitude, Address: destination.Address},
+		Stops:               stops,
+		RideType:            d.Ride.VehicleType,
+		EstimatedFare:       d.Ride.EstimatedFare,
+		MaxDistanceKm:       radius,
+		TimeoutSeconds:      svc.dispatch.TimeoutSeconds,
+		CorrelationID:       logger.GetRequestID(ctx),
+	})
+}
+
+// cancelNoDrivers отменяет поездку; пассажир получит статус через SendRide, когда событие
+// из outbox вернётся в rideStatus
+func (svc *RideService) cancelNoDrivers(ctx context.Context, ride models.Ride, now time.Time) error {
+	if err := svc.repo.ride.UpdateRide(ctx, ride.ID, types.RideStatusREQUESTED, types.RideStatusCANCELLED, types.CancelReasonNoDrivers, &now); err != nil {
+		return err
+	}
+
+	if err := svc.repo.event.Insert(ctx, ride.ID, types.RideEventCancelled, models.RideEventData{
+		OldStatus:   types.RideStatusREQUESTED,
+		NewStatus:   types.RideStatusCANCELLED,
+		PassengerID: ride.PassengerID,
+		Reason:      types.CancelReasonNoDrivers,
+		Timestamp:   now,
+	}); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(models.RideStatusUpdate{
+		RideID:    ride.ID,
+		Status:    types.RideStatusCANCELLED,
+		Timestamp: now,
+		Reason:    types.CancelReasonNoDrivers,
+	})
+	if err != nil {
+		return err
+	}
+
+	return svc.repo.outbox.Insert(ctx, models.OutboxMessage{
+		Exchange:   exchangeName,
+		RoutingKey: fmt.Sprintf("ride.status.%s", types.RideStatusCANCELLED),
+		Payload:    data,
+	})
+}
+
+// publishRideRequest кладёт запрос на подбор водителя в outbox в текущей транзакции
+func (svc *RideService) publishRideRequest(ctx context.Context, req models.RideRequestRideType) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	return svc.repo.outbox.Insert(ctx, models.OutboxMessage{
+		Exchange:      exchangeName,
+		RoutingKey:    fmt.Sprintf("ride.request.%s", req.RideType),
+		Payload:       data,
+		CorrelationID: req.CorrelationID,
+	})
+}
+
+// publishRideStatus кладёт изменение статуса в outbox; пассажир получит его, когда событие вернётся в rideStatus
+func (svc *RideService) publishRideStatus(ctx context.Context, update models.RideStatusUpdate) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	return svc.repo.outbox.Insert(ctx, models.OutboxMessage{
+		Exchange:      exchangeName,
+		RoutingKey:    fmt.Sprintf("ride.status.%s", update.Status),
+		Payload:       data,
+		CorrelationID: update.CorrelationID,
+	})
+}
+
+// publishDriverRelease кладёт в outbox событие для DAL: водитель больше не едет на эту поездку
+func (svc *RideService) publishDriverRelease(ctx context.Context, ev models.DriverReleaseEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	return svc.repo.outbox.Insert(ctx, models.OutboxMessage{
+		Exchange:      driverExchangeName,
+		RoutingKey:    fmt.Sprintf("driver.status.%s", ev.RideID),
+		Payload:       data,
+		CorrelationID: ev.CorrelationID,
+	})
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/logger"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	estimateTTL = 3 * time.Minute
+	// координаты в заказе должны совпадать с оценкой с точностью ~10 см
+	estimateCoordEpsilon = 1e-6
+	// токен оценки подписан тем же секретом, что и токены авторизации, аудитория не даёт их перепутать
+	estimateAudience = "ride-estimate"
+)
+
+// EstimateRide считает цену для всех типов машин и выдаёт токен, по которому цену можно зафиксировать
+func (svc *RideService) EstimateRide(ctx context.Context, req models.EstimateRideRequest) (models.EstimateRideResponse, error) {
+	log := svc.log.Func("RideService.EstimateRide")
+
+	trip := svc.tripVia(ctx,
+		models.Location{Lat: req.PickupLatitude, Lng: req.PickupLongitude}, req.Stops,
+		models.Location{Lat: req.DestinationLatitude, Lng: req.DestinationLongitude})
+	dist, minute := trip.DistanceKm, trip.DurationMinutes
+
+	now := time.Now()
+	multiplier := svc.surgeMultiplier(ctx, req.PickupLatitude, req.PickupLongitude)
+
+	fares := make(map[string]models.Fare, len(types.RideTypes))
+	estimates := make([]models.RideEstimate, 0, len(types.RideTypes))
+	for _, rideType := range types.RideTypes {
+		fare, err := svc.calc.Fare(ctx, rideType, now, trip, multiplier)
+		if err != nil {
+			log.Error(ctx, action.EstimateRide, "error calculating fare amount", "ride_type", rideType, "error", err)
+			return models.EstimateRideResponse{}, err
+		}
+
+		fares[rideType] = fare
+		estimates = append(estimates, models.RideEstimate{RideType: rideType, EstimatedFare: fare.Amount, Currency: fare.Currency})
+	}
+
+	expiresAt := now.Add(estimateTTL)
+
+	stops := make([]models.Position, 0, len(req.Stops))
+	for _, s := range req.Stops {
+		stops = append(stops, models.Position{Latitude: s.Lat, Longitude: s.Lng})
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, models.EstimateClaims{
+		PassengerID:          logger.GetUserID(ctx),
+		PickupLatitude:       req.PickupLatitude,
+		PickupLongitude:      req.PickupLongitude,
+		DestinationLatitude:  req.DestinationLatitude,
+		DestinationLongitude: req.DestinationLongitude,
+		Stops:                stops,
+		DistanceKm:           dist,
+		DurationMinutes:      minute,
+		Fares:                fares,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{estimateAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+
+	tokenString, err := token.SignedString([]byte(svc.secretKey))
+	if err != nil {
+		log.Error(ctx, action.EstimateRide, "error signing estimate token", "error", err)
+		return models.EstimateRideResponse{}, err
+	}
+
+	return models.EstimateRideResponse{
+		EstimatedDistanceKm:      dist,
+		EstimatedDurationMinutes: minute,
+		SurgeMultiplier:          multiplier,
+		SurgeAckRequired:         svc.surge.RequiresAck(multiplier),
+		Estimates:                estimates,
+		EstimateToken:            tokenString,
+		ExpiresAt:                expiresAt,
+	}, nil
+}
+
+// lockedEstimate проверяет токен оценки и возвращает зафиксированные в нём цену, расстояние и время
+func (svc *RideService) lockedEstimate(ctx context.Context, r models.CreateRideRequest) (models.Fare, float64, int, error) {
+	var claims models.EstimateClaims
+
+	_, err := jwt.ParseWithClaims(r.EstimateToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(svc.secretKey), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(estimateAudience))
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return models.Fare{}, 0, 0, types.ErrEstimateExpired
+		}
+		return models.Fare{}, 0, 0, types.ErrEstimateInvalid
+	}
+
+	if claims.PassengerID != logger.GetUserID(ctx) ||
+		!sameCoord(claims.PickupLatitude, r.PickupLatitude) ||
+		!sameCoord(claims.PickupLongitude, r.PickupLongitude) ||
+		!sameCoord(claims.DestinationLatitude, r.DestinationLatitude) ||
+		!sameCoord(claims.DestinationLongitude, r.DestinationLongitude) ||
+		len(claims.Stops) != len(r.Stops) {
+		return models.Fare{}, 0, 0, types.ErrEstimateInvalid
+	}
+
+	for i, s := range claims.Stops {
+		if !sameCoord(s.Latitude, r.Stops[i].Lat) || !sameCoord(s.Longitude, r.Stops[i].Lng) {
+			return models.Fare{}, 0, 0, types.ErrEstimateInvalid
+		}
+	}
+
+	fare, ok := claims.Fares[r.RideType]
+	if !ok {
+		return models.Fare{}, 0, 0, types.ErrEstimateInvalid
+	}
+
+	return fare, claims.DistanceKm, claims.DurationMinutes, nil
+}
+
+func sameCoord(a, b float64) bool {
+	return math.Abs(a-b) < estimateCoordEpsilon
+}
+
+// surgeMultiplier — если спрос посчитать не удалось, заказ не блокируется и идёт без повышения
+func (svc *RideService) surgeMultiplier(ctx context.Context, lat, lng float64) float64 {
+	m, err := svc.surge.Multiplier(ctx, lat, lng)
+	if err != nil {
+		svc.log.Func("RideService.surgeMultiplier").Error(ctx, action.EstimateRide, "error calculating surge multiplier", "error", err)
+		return 1
+	}
+	return m
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestLockedEstimateAudience(t *testing.T) {
+	const secret = "test-secret"
+	svc := &RideService{secretKey: secret}
+	ctx := logger.WithUserID(context.Background(), "passenger-1")
+
+	req := models.CreateRideRequest{
+		PickupLatitude:       43.238949,
+		PickupLongitude:      76.889709,
+		DestinationLatitude:  43.222015,
+		DestinationLongitude: 76.851248,
+		RideType:             "ECONOMY",
+	}
+
+	sign := func(t *testing.T, claims jwt.Claims) string {
+		t.Helper()
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return s
+	}
+
+	estimate := func(audience ...string) models.EstimateClaims {
+		return models.EstimateClaims{
+			PassengerID:          "passenger-1",
+			PickupLatitude:       req.PickupLatitude,
+			PickupLongitude:      req.PickupLongitude,
+			DestinationLatitude:  req.DestinationLatitude,
+			DestinationLongitude: req.DestinationLongitude,
+			DistanceKm:           5.2,
+			DurationMinutes:      11,
+			Fares:                map[string]models.Fare{"ECONOMY": {Amount: 1500, Currency: "KZT"}},
+			RegisteredClaims: jwt.RegisteredClaims{
+				Audience:  audience,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "estimate token", token: sign(t, estimate(estimateAudience))},
+		{name: "no audience", token: sign(t, estimate()), wantErr: types.ErrEstimateInvalid},
+		{name: "foreign audience", token: sign(t, estimate("ride-auth")), wantErr: types.ErrEstimateInvalid},
+		{
+			name: "auth token",
+			token: sign(t, models.Claims{
+				UserID: "passenger-1",
+				Role:   "PASSENGER",
+				RegisteredClaims: jwt.RegisteredClaims{
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+				},
+			}),
+			wantErr: types.ErrEstimateInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := req
+			r.EstimateToken = tt.token
+
+			_, _, _, err := svc.lockedEstimate(ctx, r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("lockedEstimate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListRides отдаёт страницу истории текущего пассажира; следующая страница начинается после NextCursor
+func (svc *RideService) ListRides(ctx context.Context, filter models.RideFilter) (models.RidesPage, error) {
+	log := svc.log.Func("RideService.ListRides")
+
+	var after *models.RideCursor
+	if filter.Cursor != "" {
+		c, err := decodeRideCursor(filter.Cursor)
+		if err != nil {
+			log.Warn(ctx, action.RideHistory, "invalid cursor", "cursor", filter.Cursor)
+			return models.RidesPage{}, err
+		}
+		after = &c
+	}
+
+	filter.PassengerID = logger.GetUserID(ctx)
+	limit := filter.Limit
+	// лишняя строка показывает, есть ли следующая страница
+	filter.Limit++
+
+	rides, err := svc.repo.ride.ListByPassenger(ctx, filter, after)
+	if err != nil {
+		log.Error(ctx, action.RideHistory, "error listing passenger rides", "error", err)
+		return models.RidesPage{}, err
+	}
+
+	return ridesPage(rides, limit), nil
+}
+
+// GetRideDetail отдаёт поездку только её пассажиру
+func (svc *RideService) GetRideDetail(ctx context.Context, rideID string) (models.RideDetail, error) {
+	log := svc.log.Func("RideService.GetRideDetail")
+
+	ride, err := svc.repo.ride.GetDetail(ctx, rideID)
+	if err != nil {
+		log.Error(ctx, action.RideHistory, "error retrieving ride", "ride_id", rideID, "error", err)
+		return models.RideDetail{}, err
+	}
+
+	if ride.PassengerID != logger.GetUserID(ctx) {
+		log.Warn(ctx, action.RideHistory, "ride requested by a foreign user", "ride_id", rideID)
+		return models.RideDetail{}, types.ErrRideAccessDenied
+	}
+
+	ride.Stops, err = svc.repo.stop.ListByRide(ctx, ride.RideID)
+	if err != nil {
+		log.Error(ctx, action.RideHistory, "error retrieving ride stops", "ride_id", rideID, "error", err)
+		return models.RideDetail{}, err
+	}
+
+	return ride, nil
+}
+
+// ridesPage обрезает выборку до limit; поездка сверх limit значит, что есть следующая страница
+func ridesPage(rides []models.RideSummary, limit int) models.RidesPage {
+	page := models.RidesPage{Rides: rides}
+	if len(rides) > limit {
+		page.Rides = rides[:limit]
+		last := page.Rides[limit-1]
+		page.NextCursor = encodeRideCursor(models.RideCursor{RequestedAt: last.RequestedAt, RideID: last.RideID})
+	}
+	return page
+}
+
+// курсор — base64 от "<requested_at в наносекундах>:<ride_id>"
+func encodeRideCursor(c models.RideCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.RequestedAt.UnixNano(), 10) + ":" + c.RideID))
+}
+
+func decodeRideCursor(s string) (models.RideCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return models.RideCursor{}, types.ErrInvalidCursor
+	}
+
+	ts, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return models.RideCursor{}, types.ErrInvalidCursor
+	}
+
+	nanos, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return models.RideCursor{}, types.ErrInvalidCursor
+	}
+
+	return models.RideCursor{RequestedAt: time.Unix(0, nanos), RideID: id}, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+)
+
+func TestRideCursorRoundTrip(t *testing.T) {
+	want := models.RideCursor{
+		RequestedAt: time.Date(2024, 5, 15, 12, 30, 45, 123456789, time.UTC),
+		RideID:      "550e8400-e29b-41d4-a716-446655440000",
+	}
+
+	got, err := decodeRideCursor(encodeRideCursor(want))
+	if err != nil {
+		t.Fatalf("decodeRideCursor() error = %v", err)
+	}
+	if !got.RequestedAt.Equal(want.RequestedAt) || got.RideID != want.RideID {
+		t.Errorf("decodeRideCursor() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeRideCursorInvalid(t *testing.T) {
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "%%%"},
+		{name: "no separator", cursor: enc("1715776245000000000")},
+		{name: "empty id", cursor: enc("1715776245000000000:")},
+		{name: "bad timestamp", cursor: enc("yesterday:550e8400")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeRideCursor(tt.cursor); !errors.Is(err, types.ErrInvalidCursor) {
+				t.Errorf("decodeRideCursor() error = %v, want %v", err, types.ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestRidesPage(t *testing.T) {
+	start := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	rides := []models.RideSummary{
+		{RideID: "r3", RequestedAt: start.Add(2 * time.Minute)},
+		{RideID: "r2", RequestedAt: start.Add(time.Minute)},
+		{RideID: "r1", RequestedAt: start},
+	}
+
+	// репозиторий запрашивается с limit+1, лишняя строка означает следующую страницу
+	page := ridesPage(rides, 2)
+	if len(page.Rides) != 2 {
+		t.Fatalf("len(Rides) = %d, want 2", len(page.Rides))
+	}
+	c, err := decodeRideCursor(page.NextCursor)
+	if err != nil {
+		t.Fatalf("NextCursor %q: %v", page.NextCursor, err)
+	}
+	if c.RideID != "r2" || !c.RequestedAt.Equal(rides[1].RequestedAt) {
+		t.Errorf("NextCursor points at %+v, want r2", c)
+	}
+
+	if last := ridesPage(rides, 3); last.NextCursor != "" || len(last.Rides) != 3 {
+		t.Errorf("last page = %d rides, cursor %q; want 3 rides, no cursor", len(last.Rides), last.NextCursor)
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"math"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/logger"
+	"time"
+)
+
+const (
+	// ratingPrior — столько условных пятёрок добавляется к оценкам, чтобы первая же единица
+	// не роняла рейтинг новичка до минимума
+	ratingPrior = 3
+	maxRating   = 5.0
+	minRating   = 1.0
+)
+
+// RateRide сохраняет оценку завершённой поездки от пассажира водителю или от водителя пассажиру
+// и пересчитывает средний рейтинг оценённого
+func (svc *RideService) RateRide(ctx context.Context, req models.RateRideRequest) (models.RateRideResponse, error) {
+	log := svc.log.Func("RideService.RateRide")
+
+	ride, err := svc.repo.ride.GetRide(ctx, req.RideID)
+	if err != nil {
+		log.Error(ctx, action.RateRide, "error retrieving ride", "ride_id", req.RideID, "error", err)
+		return models.RateRideResponse{}, err
+	}
+
+	rating := models.RideRating{
+		RideID:  ride.ID,
+		RaterID: logger.GetUserID(ctx),
+		Score:   req.Score,
+		Comment: req.Comment,
+		Tags:    req.Tags,
+	}
+
+	switch {
+	case logger.GetRole(ctx) == types.RoleCustomer && rating.RaterID == ride.PassengerID:
+		rating.RaterRole, rating.RateeID = types.EntityRolePassenger, ride.DriverID
+	case logger.GetRole(ctx) == types.RoleDriver && rating.RaterID == ride.DriverID:
+		rating.RaterRole, rating.RateeID = types.EntityRoleDriver, ride.PassengerID
+	default:
+		log.Warn(ctx, action.RateRide, "ride rated by a foreign user", "ride_id", ride.ID)
+		return models.RateRideResponse{}, types.ErrRideAccessDenied
+	}
+
+	if ride.Status != types.RideStatusCOMPLETED || rating.RateeID == "" {
+		return models.RateRideResponse{}, types.ErrRideNotCompleted
+	}
+
+	now := time.Now()
+	var average float64
+
+	fn := func(ctx context.Context) error {
+		if err := svc.repo.rating.LockRatee(ctx, rating.RateeID); err != nil {
+			return err
+		}
+
+		if err := svc.repo.rating.Insert(ctx, rating); err != nil {
+			return err
+		}
+
+		scores, weights, count, err := svc.repo.rating.WeightedScores(ctx, rating.RateeID, svc.rating.Window, svc.rating.Decay)
+		if err != nil {
+			return err
+		}
+		average = weightedRating(scores, weights)
+
+		if rating.RaterRole == types.EntityRolePassenger {
+			err = svc.repo.rating.SetDriverRating(ctx, rating.RateeID, average)
+		} else {
+			err = svc.repo.rating.SetPassengerRating(ctx, rating.RateeID, average, count)
+		}
+		if err != nil {
+			return err
+		}
+
+		return svc.repo.event.Insert(ctx, ride.ID, types.RideEventRated, models.RideEventData{
+			PassengerID: ride.PassengerID,
+			DriverID:    ride.DriverID,
+			Score:       rating.Score,
+			Timestamp:   now,
+		})
+	}
+
+	if err = svc.txm.Do(ctx, fn); err != nil {
+		log.Error(ctx, action.RateRide, "error saving ride rating", "ride_id", ride.ID, "error", err)
+		return models.RateRideResponse{}, err
+	}
+
+	log.Info(ctx, action.RateRide, "ride rated", "ride_id", ride.ID, "rater_role", rating.RaterRole, "score", rating.Score)
+
+	return models.RateRideResponse{
+		RideID:      ride.ID,
+		RateeID:     rating.RateeID,
+		Score:       rating.Score,
+		RateeRating: average,
+		RatedAt:     now,
+	}, nil
+}
+
+// weightedRating добавляет к взвешенным оценкам ratingPrior пятёрок и округляет до сотых, как drivers.rating
+func weightedRating(scores, weights float64) float64 {
+	avg := (scores + ratingPrior*maxRating) / (weights + ratingPrior)
+	avg = math.Round(avg*100) / 100
+	return min(max(avg, minRating), maxRating)
+}
@@ -0,0 +1,30 @@
+package service
+
+import "testing"
+
+func TestWeightedRating(t *testing.T) {
+	tests := []struct {
+		name            string
+		scores, weights float64
+		want            float64
+	}{
+		{name: "no ratings", want: 5},
+		{name: "first one-star", scores: 1, weights: 1, want: 4},
+		{name: "many one-stars", scores: 1000, weights: 1000, want: 1.01},
+		{name: "all fives", scores: 50, weights: 10, want: 5},
+		{name: "mixed", scores: 4*3 + 3*2, weights: 5, want: 4.13},
+		{name: "fractional weights", scores: 2 * 0.5, weights: 0.5, want: 4.57},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := weightedRating(tt.scores, tt.weights)
+			if got != tt.want {
+				t.Errorf("weightedRating(%v, %v) = %v, want %v", tt.scores, tt.weights, got, tt.want)
+			}
+			if got < minRating || got > maxRating {
+				t.Errorf("weightedRating() = %v out of [%v, %v]", got, minRating, maxRating)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/pkg/logger"
+	"time"
+)
+
+const (
+	schedulePollInterval = 15 * time.Second
+	scheduleBatchSize    = 20
+)
+
+// checkSchedule проверяет, что время подачи укладывается в [MinAdvanceMinutes, MaxAdvanceDays] от now
+func (svc *RideService) checkSchedule(at, now time.Time) error {
+	earliest := now.Add(time.Duration(svc.schedule.MinAdvanceMinutes) * time.Minute)
+	latest := now.AddDate(0, 0, svc.schedule.MaxAdvanceDays)
+
+	if at.Before(earliest) {
+		return fmt.Errorf("%w: pickup must be at least %d minutes ahead", types.ErrInvalidSchedule, svc.schedule.MinAdvanceMinutes)
+	}
+	if at.After(latest) {
+		return fmt.Errorf("%w: pickup must be within %d days", types.ErrInvalidSchedule, svc.schedule.MaxAdvanceDays)
+	}
+	return nil
+}
+
+// dispatchScheduled за LeadMinutes до подачи переводит запланированные поездки в REQUESTED
+// и публикует их в обычный ride.request.*; дальше ими занимается dispatchTimeouts
+func (svc *RideService) dispatchScheduled(ctx context.Context) {
+	log := svc.log.Func("RideService.dispatchScheduled")
+
+	ticker := time.NewTicker(schedulePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug(ctx, action.ScheduleRide, "schedule dispatcher stopped")
+			return
+		case <-ticker.C:
+			for {
+				n, err := svc.promoteDue(ctx)
+				if err != nil {
+					log.Error(ctx, action.ScheduleRide, "failed to dispatch scheduled rides", "error", err)
+					break
+				}
+				if n < scheduleBatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (svc *RideService) promoteDue(ctx context.Context) (int, error) {
+	log := svc.log.Func("RideService.promoteDue")
+
+	now := time.Now()
+	lead := time.Duration(svc.schedule.LeadMinutes) * time.Minute
+
+	var n int
+
+	fn := func(ctx context.Context) error {
+		rides, err := svc.repo.ride.ListDueScheduled(ctx, now.Add(lead), scheduleBatchSize)
+		if err != nil {
+			return err
+		}
+		n = len(rides)
+
+		for _, ride := range rides {
+			if err = svc.promoteRide(ctx, ride, now); err != nil {
+				return fmt.Errorf("failed to promote ride %s: %w", ride.ID, err)
+			}
+			log.Info(ctx, action.ScheduleRide, "scheduled ride sent to matching", "ride_id", ride.ID, "scheduled_at", ride.ScheduledAt)
+		}
+
+		return nil
+	}
+
+	if err := svc.txm.Do(ctx, fn); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// promoteRide публикует запрос на подбор; пассажир узнает о начале поиска через эхо ride.status.REQUESTED
+func (svc *RideService) promoteRide(ctx context.Context, ride models.Ride, now time.Time) error {
+	pickup, err := svc.repo.cord.GetCoordinate(ctx, ride.PickupCoordinateId)
+	if err != nil {
+		return err
+	}
+
+	destination, err := svc.repo.cord.GetCoordinate(ctx, ride.DestinationCoordinateId)
+	if err != nil {
+		return err
+	}
+
+	stops, err := svc.stopLocations(ctx, ride.ID)
+	if err != nil {
+		return err
+	}
+
+	if err = svc.repo.ride.PromoteScheduled(ctx, ride.ID, now); err != nil {
+		return err
+	}
+
+	if err = svc.repo.event.Insert(ctx, ride.ID, types.RideEventRequested, models.RideEventData{
+		OldStatus:     types.RideStatusSCHEDULED,
+		NewStatus:     types.RideStatusREQUESTED,
+		PassengerID:   ride.PassengerID,
+		Location:      &models.LocationDriver{Lat: pickup.Latitude, Lng: pickup.Longitude},
+		EstimatedFare: ride.EstimatedFare,
+		ScheduledAt:   &ride.ScheduledAt,
+		Timestamp:     now,
+	}); err != nil {
+		return err
+	}
+
+	if err = svc.publishRideRequest(ctx, models.RideRequestRideType{
+		RideID:              ride.ID,
+		RideNumber:          ride.RideNumber,
+		PickupLocation:      models.Location{Lat: pickup.Latitude, Lng: pickup.Longitude, Address: pickup.Address},
+		DestinationLocation: models.Location{Lat: destination.Latitude, Lng: destination.Longitude, Address: destination.Address},
+		Stops:               stops,
+		RideType:            ride.VehicleType,
+		EstimatedFare:       ride.EstimatedFare,
+		MaxDistanceKm:       searchRadiusKm,
+		TimeoutSeconds:      svc.dispatch.TimeoutSeconds,
+		CorrelationID:       logger.GetRequestID(ctx),
+	}); err != nil {
+		return err
+	}
+
+	return svc.publishRideStatus(ctx, models.RideStatusUpdate{
+		RideID:      ride.ID,
+		Status:      types.RideStatusREQUESTED,
+		Timestamp:   now,
+		ScheduledAt: &ride.ScheduledAt,
+	})
+}
+
+// UpdateScheduledRide меняет время подачи и адреса поездки, пока она не ушла на подбор, и пересчитывает цену
+func (svc *RideService) UpdateScheduledRide(ctx context.Context, req models.UpdateRideRequest) (models.CreateRideResponse, error) {
+	log := svc.log.Func("RideService.UpdateScheduledRide")
+
+	ride, err := svc.repo.ride.GetRide(ctx, req.RideID)
+	if err != nil {
+		log.Error(ctx, action.ScheduleRide, "error retrieving ride", "error", err)
+		return models.CreateRideResponse{}, err
+	}
+
+	if ride.PassengerID != logger.GetUserID(ctx) {
+		log.Warn(ctx, action.ScheduleRide, "ride belongs to another passenger", "ride_id", ride.ID)
+		return models.CreateRideResponse{}, types.ErrRideAccessDenied
+	}
+
+	if ride.Status != types.RideStatusSCHEDULED {
+		log.Warn(ctx, action.ScheduleRide, "ride is not scheduled", "ride_id", ride.ID, "status", ride.Status)
+		return models.CreateRideResponse{}, types.ErrRideNotScheduled
+	}
+
+	now := time.Now()
+	if req.ScheduledAt != nil {
+		if err = svc.checkSchedule(*req.ScheduledAt, now); err != nil {
+			log.Warn(ctx, action.ScheduleRide, "invalid scheduled time", "scheduled_at", *req.ScheduledAt, "error", err)
+			return models.CreateRideResponse{}, err
+		}
+		ride.ScheduledAt = *req.ScheduledAt
+	}
+
+	pickup, err := svc.repo.cord.GetCoordinate(ctx, ride.PickupCoordinateId)
+	if err != nil {
+		log.Error(ctx, action.ScheduleRide, "error retrieving pickup coordinate", "error", err)
+		return models.CreateRideResponse{}, err
+	}
+	destination, err := svc.repo.cord.GetCoordinate(ctx, ride.DestinationCoordinateId)
+	if err != nil {
+		log.Error(ctx, action.ScheduleRide, "error retrieving destination coordinate", "error", err)
+		return models.CreateRideResponse{}, err
+	}
+
+	pickupLoc := models.Location{Lat: pickup.Latitude, Lng: pickup.Longitude, Address: pickup.Address}
+	if req.PickupLocation != nil {
+		pickupLoc = *req.PickupLocation
+	}
+	destinationLoc := models.Location{Lat: destination.Latitude, Lng: destination.Longitude, Address: destination.Address}
+	if req.DestinationLocation != nil {
+		destinationLoc = *req.DestinationLocation
+	}
+
+	stops, err := svc.stopLocations(ctx, ride.ID)
+	if err != nil {
+		log.Error(ctx, action.ScheduleRide, "error retrieving ride stops", "error", err)
+		return models.CreateRideResponse{}, err
+	}
+
+	trip := svc.tripVia(ctx, pickupLoc, stops, destinationLoc)
+
+	fare, err := svc.calc.Fare(ctx, ride.VehicleType, ride.ScheduledAt, trip, 1)
+	if err != nil {
+		log.Error(ctx, action.ScheduleRide, "error calculating fare amount", "error", err)
+		return models.CreateRideResponse{}, err
+	}
+	ride.EstimatedFare = fare.Amount
+	ride.TariffID = fare.TariffID
+
+	newCoordinate := func(loc models.Location) models.Coordinate {
+		return models.Coordinate{
+			EntityID:        ride.PassengerID,
+			EntityType:      types.EntityRolePassenger,
+			Address:         loc.Address,
+			Latitude:        loc.Lat,
+			Longitude:       loc.Lng,
+			FareAmount:      fare.Amount,
+			DurationMinutes: trip.DurationMinutes,
+			DistanceKM:      trip.DistanceKm,
+			IsCurrent:       true,
+		}
+	}
+
+	fn := func(ctx context.Context) error {
+		if req.PickupLocation != nil {
+			if ride.PickupCoordinateId, err = svc.repo.cord.CreateNewCoordinate(ctx, newCoordinate(pickupLoc)); err != nil {
+				return err
+			}
+		}
+		if req.DestinationLocation != nil {
+			if ride.DestinationCoordinateId, err = svc.repo.cord.CreateNewCoordinate(ctx, newCoordinate(destinationLoc)); err != nil {
+				return err
+			}
+		}
+
+		if err = svc.repo.ride.UpdateScheduled(ctx, ride); err != nil {
+			return err
+		}
+
+		if err = svc.repo.event.Insert(ctx, ride.ID, types.RideEventRescheduled, models.RideEventData{
+			OldStatus:     types.RideStatusSCHEDULED,
+			NewStatus:     types.RideStatusSCHEDULED,
+			PassengerID:   ride.PassengerID,
+			Location:      &models.LocationDriver{Lat: pickupLoc.Lat, Lng: pickupLoc.Lng},
+			EstimatedFare: fare.Amount,
+			ScheduledAt:   &ride.ScheduledAt,
+			Timestamp:     now,
+			CorrelationID: logger.GetRequestID(ctx),
+		}); err != nil {
+			return err
+		}
+
+		return svc.publishRideStatus(ctx, models.RideStatusUpdate{
+			RideID:        ride.ID,
+			Status:        types.RideStatusSCHEDULED,
+			Timestamp:     now,
+			ScheduledAt:   &ride.ScheduledAt,
+			CorrelationID: logger.GetRequestID(ctx),
+		})
+	}
+
+	if err = svc.txm.Do(ctx, fn); err != nil {
+		log.Error(ctx, action.ScheduleRide, "error updating scheduled ride", "error", err)
+		return models.CreateRideResponse{}, err
+	}
+
+	log.Info(ctx, action.ScheduleRide, "scheduled ride updated", "ride_id", ride.ID, "scheduled_at", ride.ScheduledAt)
+
+	return models.CreateRideResponse{
+		RideID:                   ride.ID,
+		RideNumber:               ride.RideNumber,
+		Status:                   types.RideStatusSCHEDULED,
+		EstimatedFare:            fare.Amount,
+		Currency:                 fare.Currency,
+		SurgeMultiplier:          fare.SurgeMultiplier,
+		EstimatedDurationMinutes: trip.DurationMinutes,
+		EstimatedDistanceKm:      trip.DistanceKm,
+		ScheduledAt:              &ride.ScheduledAt,
+	}, nil
+}
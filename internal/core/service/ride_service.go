@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"ride-hail/config"
 	"ride-hail/internal/core/domain/action"
 	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/state"
 	"ride-hail/internal/core/domain/types"
 	"ride-hail/internal/core/ports"
 	"ride-hail/internal/core/service/calculator"
+	"ride-hail/internal/core/service/routing"
+	"ride-hail/internal/core/service/surge"
 	"ride-hail/pkg/logger"
 	"ride-hail/pkg/txm"
 	"time"
@@ -21,31 +25,51 @@ type RideService struct {
 	wsm       ports.PassengerWSManager
 	txm       txm.Manager
 	msgBroker MsgBroker
+	calc      *calculator.Calculator
+	surge     *surge.Pricer
+	routing   *routing.Router
+	dispatch  config.Dispatch
+	schedule  config.Schedule
+	rating    config.Rating
+	secretKey string
 }
 
 type MsgBroker struct {
-	producer            ports.RideProducer
 	consumerLocation    ports.LocationSubscriber
 	consumerDriverMatch ports.DriverMatchSubscriber
 	consumerRideStatus  ports.RideStatusSubscriber
 }
 
 type rideRepository struct {
-	ride ports.RideRepository
-	cord ports.CoordinatesRepository
+	ride   ports.RideRepository
+	cord   ports.CoordinatesRepository
+	stop   ports.RideStopRepository
+	rating ports.RatingRepository
+	event  ports.RideEventRepository
+	outbox ports.OutboxRepository
 }
 
-func NewRideService(log *logger.Logger, txm txm.Manager, rideRepo ports.RideRepository, cordRepo ports.CoordinatesRepository, wsm ports.PassengerWSManager, rPub ports.RideProducer, consumerLocation ports.LocationSubscriber, consumerDriverMatch ports.DriverMatchSubscriber, consumerRideStatus ports.RideStatusSubscriber) *RideService {
+func NewRideService(log *logger.Logger, cfg config.Config, txm txm.Manager, calc *calculator.Calculator, pricer *surge.Pricer, router *routing.Router, rideRepo ports.RideRepository, cordRepo ports.CoordinatesRepository, stopRepo ports.RideStopRepository, ratingRepo ports.RatingRepository, eventRepo ports.RideEventRepository, outboxRepo ports.OutboxRepository, wsm ports.PassengerWSManager, consumerLocation ports.LocationSubscriber, consumerDriverMatch ports.DriverMatchSubscriber, consumerRideStatus ports.RideStatusSubscriber) *RideService {
 	return &RideService{
-		log: log,
-		txm: txm,
-		wsm: wsm,
+		log:       log,
+		txm:       txm,
+		wsm:       wsm,
+		calc:      calc,
+		surge:     pricer,
+		routing:   router,
+		dispatch:  cfg.Dispatch,
+		schedule:  cfg.Schedule,
+		rating:    cfg.Rating,
+		secretKey: cfg.JWT.Secret,
 		repo: rideRepository{
-			ride: rideRepo,
-			cord: cordRepo,
+			ride:   rideRepo,
+			cord:   cordRepo,
+			stop:   stopRepo,
+			rating: ratingRepo,
+			event:  eventRepo,
+			outbox: outboxRepo,
 		},
 		msgBroker: MsgBroker{
-			producer:            rPub,
 			consumerLocation:    consumerLocation,
 			consumerRideStatus:  consumerRideStatus,
 			consumerDriverMatch: consumerDriverMatch,
@@ -53,44 +77,25 @@ func NewRideService(log *logger.Logger, txm txm.Manager, rideRepo ports.RideRepo
 	}
 }
 
-const exchangeName = "ride_topic"
+const (
+	exchangeName   = "ride_topic"
+	searchRadiusKm = 5.0
+)
 
 func (svc *RideService) StartService(ctx context.Context) {
 	log := svc.log.Func("RideService.StartService")
 
-	go svc.runWithRetry(ctx, svc.driverMatch, "driverMatch")
-	go svc.runWithRetry(ctx, svc.driverLocation, "driverLocation")
-	go svc.runWithRetry(ctx, svc.rideStatus, "rideStatus")
+	go runWithRetry(ctx, svc.log, svc.driverMatch, "RideService.driverMatch")
+	go runWithRetry(ctx, svc.log, svc.driverLocation, "RideService.driverLocation")
+	go runWithRetry(ctx, svc.log, svc.rideStatus, "RideService.rideStatus")
+	go svc.dispatchTimeouts(ctx)
+	go svc.dispatchScheduled(ctx)
 
 	log.Debug(ctx, action.ServiceRide, "RideService started")
 	<-ctx.Done()
 	log.Debug(ctx, action.ServiceRide, "RideService stopping")
 }
 
-func (svc *RideService) runWithRetry(ctx context.Context, fn func(ctx context.Context) error, name string) {
-	log := svc.log.Func("RideService." + name)
-	backoff := time.Second
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Debug(ctx, action.ServiceRide, "stopping service")
-			return
-		default:
-			err := fn(ctx)
-			if err != nil {
-				log.Error(ctx, action.ServiceRide, fmt.Sprintf("%s failed, retrying", name), "error", err)
-				time.Sleep(backoff)
-				if backoff < 30*time.Second {
-					backoff *= 2
-				}
-			} else {
-				backoff = time.Second
-			}
-		}
-	}
-}
-
 func (svc *RideService) rideStatus(ctx context.Context) error {
 	log := svc.log.Func("RideService.rideStatus")
 
@@ -110,18 +115,51 @@ func (svc *RideService) rideStatus(ctx context.Context) error {
 				log.Debug(ctx, action.ServiceRide, "ride status channel closed, exiting")
 				return fmt.Errorf("ride status channel closed")
 			}
-			svc.parsingRideStatus(ctx, msg)
+			msg.Done(svc.parsingRideStatus(ctx, msg.Event))
 		}
 	}
 }
 
-func (svc *RideService) parsingRideStatus(ctx context.Context, msg models.RideStatusEvent) {
+func (svc *RideService) parsingRideStatus(ctx context.Context, msg models.RideStatusEvent) error {
 	log := svc.log.Func("RideService.parsingRideStatus")
 	ctxNew := logger.WithRequestID(ctx, msg.CorrelationID)
 
-	if err := svc.repo.ride.UpdateRide(ctx, msg.RideID, msg.Status, "", &msg.Timestamp); err != nil {
-		log.Error(ctxNew, action.ServiceRide, "failed to update ride in database", "error", err)
-		return
+	ride, err := svc.repo.ride.GetRide(ctx, msg.RideID)
+	if err != nil {
+		log.Error(ctxNew, action.ServiceRide, "failed to get ride", "error", err)
+		return skipIfNotFound(err)
+	}
+
+	// статус уже записан инициатором события, остаётся только уведомить пассажира
+	if ride.Status != msg.Status {
+		actor := state.ActorSystem
+		if msg.DriverID != "" && msg.DriverID == ride.DriverID {
+			actor = state.ActorDriver
+		}
+
+		if err = state.CanTransition(ride.Status, msg.Status, actor); err != nil {
+			log.Warn(ctxNew, action.ServiceRide, "rejected ride status event", "ride_id", ride.ID, "error", err)
+			return nil
+		}
+
+		fn := func(ctx context.Context) error {
+			if err := svc.repo.ride.UpdateRide(ctx, ride.ID, ride.Status, msg.Status, "", &msg.Timestamp); err != nil {
+				return err
+			}
+
+			return svc.repo.event.Insert(ctx, ride.ID, types.RideEventForStatus(msg.Status), models.RideEventData{
+				OldStatus:     ride.Status,
+				NewStatus:     msg.Status,
+				DriverID:      msg.DriverID,
+				Timestamp:     msg.Timestamp,
+				CorrelationID: msg.CorrelationID,
+			})
+		}
+
+		if err = svc.txm.Do(ctx, fn); err != nil {
+			log.Error(ctxNew, action.ServiceRide, "failed to update ride in database", "error", err)
+			return skipIfConflict(err)
+		}
 	}
 
 	if data, err := json.Marshal(models.RideStatusUpdate{
@@ -129,14 +167,17 @@ func (svc *RideService) parsingRideStatus(ctx context.Context, msg models.RideSt
 		Status:        msg.Status,
 		Timestamp:     msg.Timestamp,
 		DriverID:      msg.DriverID,
+		Reason:        msg.Reason,
+		ScheduledAt:   msg.ScheduledAt,
 		CorrelationID: msg.CorrelationID,
 	}); err != nil {
 		log.Error(ctxNew, action.ServiceRide, "failed to marshal ride status", "error", err)
-		return
-	} else if err = svc.wsm.SendRide(ctxNew, msg.RideID, data); err != nil {
+		return nil
+	} else if err = svc.wsm.SendRide(ctxNew, ride.PassengerID, data); err != nil {
 		log.Error(ctxNew, action.ServiceRide, "failed to send ride status update", "error", err)
-		return
+		return nil
 	}
+	return nil
 }
 
 func (svc *RideService) driverMatch(ctx context.Context) error {
@@ -158,25 +199,56 @@ func (svc *RideService) driverMatch(ctx context.Context) error {
 				log.Debug(ctx, action.ServiceRide, "driverMatch channel closed")
 				return fmt.Errorf("driverMatch channel closed")
 			}
-			go svc.parsingDriverMatch(ctx, msg)
+			go func() { msg.Done(svc.parsingDriverMatch(ctx, msg.Event)) }()
 		}
 	}
 }
 
-func (svc *RideService) parsingDriverMatch(ctx context.Context, driverResp models.DriverResponseEvent) {
+func (svc *RideService) parsingDriverMatch(ctx context.Context, driverResp models.DriverResponseEvent) error {
 	log := svc.log.Func("RideService.parsingDriverStatus")
 	ctxNew := logger.WithRequestID(ctx, driverResp.CorrelationID)
 	now := time.Now()
 
+	if !driverResp.Accepted || driverResp.DriverID == "" {
+		log.Debug(ctxNew, action.ServiceRide, "driver response is not an acceptance, skipping", "ride_id", driverResp.RideID)
+		return nil
+	}
+
 	ride, err := svc.repo.ride.GetRide(ctx, driverResp.RideID)
 	if err != nil {
-		log.Error(ctxNew, action.ServiceRide, "failed to get ride")
-		return
+		log.Error(ctxNew, action.ServiceRide, "failed to get ride", "error", err)
+		return skipIfNotFound(err)
+	}
+
+	if err = state.CanTransition(ride.Status, types.RideStatusMATCHED, state.ActorSystem); err != nil {
+		log.Warn(ctxNew, action.ServiceRide, "ride cannot be matched", "ride_id", ride.ID, "error", err)
+		return svc.releaseUnmatchedDriver(ctxNew, ride, driverResp)
+	}
+
+	fn := func(ctx context.Context) error {
+		if err := svc.repo.ride.UpdateMatchedRide(ctx, driverResp.RideID, driverResp.DriverID, now); err != nil {
+			return err
+		}
+
+		return svc.repo.event.Insert(ctx, ride.ID, types.RideEventDriverMatched, models.RideEventData{
+			OldStatus:     ride.Status,
+			NewStatus:     types.RideStatusMATCHED,
+			DriverID:      driverResp.DriverID,
+			Timestamp:     now,
+			CorrelationID: driverResp.CorrelationID,
+		})
 	}
 
-	if err = svc.repo.ride.UpdateMatchedRide(ctx, driverResp.RideID, driverResp.DriverID, now); err != nil {
-		log.Error(ctxNew, action.ServiceRide, "failed to update matched ride")
-		return
+	if err = svc.txm.Do(ctx, fn); err != nil {
+		log.Error(ctxNew, action.ServiceRide, "failed to update matched ride", "error", err)
+		if !errors.Is(err, types.ErrRideStatusConflict) {
+			return err
+		}
+
+		if ride, err = svc.repo.ride.GetRide(ctx, driverResp.RideID); err != nil {
+			return skipIfNotFound(err)
+		}
+		return svc.releaseUnmatchedDriver(ctxNew, ride, driverResp)
 	}
 
 	if data, err := json.Marshal(models.RideStatusUpdate{
@@ -187,11 +259,12 @@ func (svc *RideService) parsingDriverMatch(ctx context.Context, driverResp model
 		CorrelationID: driverResp.CorrelationID,
 	}); err != nil {
 		log.Error(ctxNew, action.ServiceRide, "failed to marshal ride status", "error", err)
-		return
-	} else if err = svc.wsm.SendRide(ctx, ride.ID, data); err != nil {
+		return nil
+	} else if err = svc.wsm.SendRide(ctx, ride.PassengerID, data); err != nil {
 		log.Error(ctxNew, action.ServiceRide, "failed to send ride-status update")
-		return
+		return nil
 	}
+	return nil
 }
 
 func (svc *RideService) driverLocation(ctx context.Context) error {
@@ -213,46 +286,112 @@ func (svc *RideService) driverLocation(ctx context.Context) error {
 				log.Debug(ctx, action.ServiceRide, "driverLocation channel closed")
 				return fmt.Errorf("driverLocation channel closed")
 			}
-			go svc.processingMsg(ctx, msg)
+			go func() { msg.Done(svc.processingMsg(ctx, msg.Event)) }()
 		}
 	}
 
 }
 
-func (svc *RideService) processingMsg(ctx context.Context, msg models.DriverLocationUpdate) {
+func (svc *RideService) processingMsg(ctx context.Context, msg models.DriverLocationUpdate) error {
 	log := svc.log.Func("RideService.processingMsg")
 
+	if msg.RideID == "" {
+		return nil
+	}
+
 	ride, err := svc.repo.ride.GetRide(ctx, msg.RideID)
 	if err != nil {
 		log.Error(ctx, action.ServiceRide, "failed to get ride", "error", err)
-		return
+		return skipIfNotFound(err)
+	}
+
+	// в журнал попадают только точки во время самой поездки
+	if ride.Status == types.RideStatusIN_PROGRESS {
+		fn := func(ctx context.Context) error {
+			return svc.repo.event.Insert(ctx, ride.ID, types.RideEventLocationUpdated, models.RideEventData{
+				DriverID:  msg.DriverID,
+				Location:  &models.LocationDriver{Lat: msg.Location.Lat, Lng: msg.Location.Lng},
+				SpeedKmh:  msg.SpeedKmh,
+				Heading:   msg.Heading,
+				Timestamp: msg.Timestamp,
+			})
+		}
+
+		if err = svc.txm.Do(ctx, fn); err != nil {
+			log.Error(ctx, action.ServiceRide, "failed to save location event", "error", err)
+		}
 	}
 
 	if data, err := json.Marshal(msg); err != nil {
 		log.Error(ctx, action.ServiceRide, "failed to marshal driver location", "error", err)
-		return
+		return nil
 	} else if err = svc.wsm.SendRide(ctx, ride.PassengerID, data); err != nil {
 		log.Error(ctx, action.ServiceRide, "failed to send driver location update", "error", err)
-		return
+		return nil
 	}
+	return nil
 }
 
 func (svc *RideService) CreateNewRide(ctx context.Context, r models.CreateRideRequest) (models.CreateRideResponse, error) {
 	log := svc.log.Func("RideService.CreateNewRide")
 
-	dist := calculator.Distance(r.PickupLatitude, r.PickupLongitude, r.DestinationLatitude, r.DestinationLongitude)
-	minute := calculator.Duration(dist)
-	fareAmount, err := calculator.CalculateFare(r.RideType, dist, minute)
-	if err != nil {
-		log.Error(ctx, action.CreateRide, "error calculating fare amount", "error", err)
-		return models.CreateRideResponse{}, err
+	var (
+		fare   models.Fare
+		dist   float64
+		minute int
+		err    error
+	)
+
+	status := types.RideStatusREQUESTED
+	if r.ScheduledAt != nil {
+		if err = svc.checkSchedule(*r.ScheduledAt, time.Now()); err != nil {
+			log.Warn(ctx, action.CreateRide, "invalid scheduled time", "scheduled_at", *r.ScheduledAt, "error", err)
+			return models.CreateRideResponse{}, err
+		}
+		status = types.RideStatusSCHEDULED
+	}
+
+	if r.EstimateToken != "" {
+		// цена зафиксирована оценкой, пересчитывать её нельзя
+		if fare, dist, minute, err = svc.lockedEstimate(ctx, r); err != nil {
+			log.Warn(ctx, action.CreateRide, "estimate token rejected", "error", err)
+			return models.CreateRideResponse{}, err
+		}
+	} else {
+		trip := svc.tripVia(ctx,
+			models.Location{Lat: r.PickupLatitude, Lng: r.PickupLongitude}, r.Stops,
+			models.Location{Lat: r.DestinationLatitude, Lng: r.DestinationLongitude})
+		dist, minute = trip.DistanceKm, trip.DurationMinutes
+
+		// запланированная поездка считается по тарифу на время подачи и без surge: текущий спрос к ней не относится
+		at, multiplier := time.Now(), 1.0
+		if r.ScheduledAt != nil {
+			at = *r.ScheduledAt
+		} else {
+			multiplier = svc.surgeMultiplier(ctx, r.PickupLatitude, r.PickupLongitude)
+		}
+		if fare, err = svc.calc.Fare(ctx, r.RideType, at, trip, multiplier); err != nil {
+			log.Error(ctx, action.CreateRide, "error calculating fare amount", "error", err)
+			return models.CreateRideResponse{}, err
+		}
+	}
+
+	if svc.surge.RequiresAck(fare.SurgeMultiplier) && r.AcceptedSurgeMultiplier < fare.SurgeMultiplier {
+		log.Info(ctx, action.CreateRide, "surge multiplier not acknowledged", "multiplier", fare.SurgeMultiplier, "accepted", r.AcceptedSurgeMultiplier)
+		return models.CreateRideResponse{}, fmt.Errorf("%w: current multiplier is %.1f", types.ErrSurgeNotAcknowledged, fare.SurgeMultiplier)
 	}
+	fareAmount := fare.Amount
 
 	newRide := models.Ride{
-		PassengerID:   logger.GetUserID(ctx),
-		VehicleType:   r.RideType,
-		Status:        types.RideStatusREQUESTED,
-		EstimatedFare: fareAmount,
+		PassengerID:     logger.GetUserID(ctx),
+		VehicleType:     r.RideType,
+		Status:          status,
+		EstimatedFare:   fareAmount,
+		TariffID:        fare.TariffID,
+		SurgeMultiplier: fare.SurgeMultiplier,
+	}
+	if r.ScheduledAt != nil {
+		newRide.ScheduledAt = *r.ScheduledAt
 	}
 
 	fn := func(ctx context.Context) error {
@@ -299,25 +438,47 @@ func (svc *RideService) CreateNewRide(ctx context.Context, r models.CreateRideRe
 			return err
 		}
 
-		if data, err := json.Marshal(models.RideRequestRideType{
+		if err = svc.saveStops(ctx, newRide.ID, newRide.PassengerID, r.Stops); err != nil {
+			log.Error(ctx, action.CreateRide, "error saving ride stops", "error", err)
+			return err
+		}
+
+		eventType := types.RideEventRequested
+		if status == types.RideStatusSCHEDULED {
+			eventType = types.RideEventScheduled
+		}
+
+		if err = svc.repo.event.Insert(ctx, newRide.ID, eventType, models.RideEventData{
+			NewStatus:     status,
+			PassengerID:   newRide.PassengerID,
+			Location:      &models.LocationDriver{Lat: r.PickupLatitude, Lng: r.PickupLongitude},
+			EstimatedFare: fareAmount,
+			Timestamp:     time.Now(),
+			CorrelationID: logger.GetRequestID(ctx),
+		}); err != nil {
+			log.Error(ctx, action.CreateRide, "error saving ride event", "error", err)
+			return err
+		}
+
+		// на подбор запланированную поездку отправит dispatchScheduled
+		if status == types.RideStatusSCHEDULED {
+			return nil
+		}
+
+		if err = svc.publishRideRequest(ctx, models.RideRequestRideType{
 			RideID:              newRide.ID,
 			RideNumber:          newRide.RideNumber,
 			PickupLocation:      models.Location{Lat: r.PickupLatitude, Lng: r.PickupLongitude, Address: r.PickupAddress},
 			DestinationLocation: models.Location{Lat: r.DestinationLatitude, Lng: r.DestinationLongitude, Address: r.DestinationAddress},
+			Stops:               r.Stops,
 			RideType:            r.RideType,
 			EstimatedFare:       fareAmount,
-			MaxDistanceKm:       dist,
-			TimeoutSeconds:      30,
+			MaxDistanceKm:       searchRadiusKm,
+			TimeoutSeconds:      svc.dispatch.TimeoutSeconds,
 			CorrelationID:       logger.GetRequestID(ctx),
 		}); err != nil {
-			log.Error(ctx, action.CreateRide, "error marshalling new ride", "error", err)
+			log.Error(ctx, action.CreateRide, "error saving ride request to outbox", "error", err)
 			return err
-		} else {
-			routingKey := fmt.Sprintf("ride.request.%s", r.RideType)
-			if err = svc.msgBroker.producer.Producer(exchangeName, routingKey, data); err != nil {
-				log.Error(ctx, action.CreateRide, "error publishing ride", "error", err)
-				return err
-			}
 		}
 
 		return nil
@@ -330,10 +491,13 @@ func (svc *RideService) CreateNewRide(ctx context.Context, r models.CreateRideRe
 	return models.CreateRideResponse{
 		RideID:                   newRide.ID,
 		RideNumber:               newRide.RideNumber,
-		Status:                   types.RideStatusREQUESTED,
+		Status:                   status,
 		EstimatedFare:            fareAmount,
+		Currency:                 fare.Currency,
+		SurgeMultiplier:          fare.SurgeMultiplier,
 		EstimatedDurationMinutes: minute,
 		EstimatedDistanceKm:      dist,
+		ScheduledAt:              r.ScheduledAt,
 	}, nil
 }
 
@@ -346,24 +510,42 @@ func (svc *RideService) CloseRide(ctx context.Context, req models.CloseRideReque
 		return models.CloseRideResponse{}, err
 	}
 
-	if ride.Status != types.RideStatusREQUESTED {
-		log.Error(ctx, action.CloseRide, "ride.status is not requested", "error", err)
-		return models.CloseRideResponse{}, errors.New("ride.status is not requested")
+	if ride.PassengerID != logger.GetUserID(ctx) {
+		log.Warn(ctx, action.CloseRide, "ride belongs to another passenger", "ride_id", ride.ID)
+		return models.CloseRideResponse{}, types.ErrRideAccessDenied
 	}
 
-	fn := func(ctx context.Context) error {
-		now := time.Now()
+	if err = state.CanTransition(ride.Status, types.RideStatusCANCELLED, state.ActorPassenger); err != nil {
+		log.Warn(ctx, action.CloseRide, "ride cannot be cancelled", "status", ride.Status, "error", err)
+		return models.CloseRideResponse{}, err
+	}
 
-		if err = svc.repo.ride.UpdateRide(ctx, ride.ID, types.RideStatusCANCELLED, req.Reason, &now); err != nil {
+	now := time.Now()
+	fn := func(ctx context.Context) error {
+		if err = svc.repo.ride.UpdateRide(ctx, ride.ID, ride.Status, types.RideStatusCANCELLED, req.Reason, &now); err != nil {
 			log.Error(ctx, action.CloseRide, "error updating ride", "error", err)
 			return err
 		}
 
+		if err = svc.repo.event.Insert(ctx, ride.ID, types.RideEventCancelled, models.RideEventData{
+			OldStatus:     ride.Status,
+			NewStatus:     types.RideStatusCANCELLED,
+			PassengerID:   ride.PassengerID,
+			DriverID:      ride.DriverID,
+			Reason:        req.Reason,
+			Timestamp:     now,
+			CorrelationID: logger.GetRequestID(ctx),
+		}); err != nil {
+			log.Error(ctx, action.CloseRide, "error saving ride event", "error", err)
+			return err
+		}
+
 		data, err := json.Marshal(models.RideStatusUpdate{
 			RideID:        ride.ID,
 			Status:        types.RideStatusCANCELLED,
 			Timestamp:     now,
 			DriverID:      ride.DriverID,
+			Reason:        req.Reason,
 			CorrelationID: logger.GetRequestID(ctx),
 		})
 		if err != nil {
@@ -371,9 +553,28 @@ func (svc *RideService) CloseRide(ctx context.Context, req models.CloseRideReque
 			return err
 		}
 
-		routingKey := fmt.Sprintf("ride.status.%s", types.RideStatusCANCELLED)
-		if err = svc.msgBroker.producer.Producer(exchangeName, routingKey, data); err != nil {
-			log.Error(ctx, action.CloseRide, "error publishing ride status", "error", err)
+		if err = svc.repo.outbox.Insert(ctx, models.OutboxMessage{
+			Exchange:      exchangeName,
+			RoutingKey:    fmt.Sprintf("ride.status.%s", types.RideStatusCANCELLED),
+			Payload:       data,
+			CorrelationID: logger.GetRequestID(ctx),
+		}); err != nil {
+			log.Error(ctx, action.CloseRide, "error saving ride status to outbox", "error", err)
+			return err
+		}
+
+		// назначенный водитель уже едет к пассажиру, DAL вернёт его в AVAILABLE
+		if ride.DriverID != "" {
+			if err = svc.publishDriverRelease(ctx, models.DriverReleaseEvent{
+				RideID:        ride.ID,
+				DriverID:      ride.DriverID,
+				Reason:        req.Reason,
+				Timestamp:     now,
+				CorrelationID: logger.GetRequestID(ctx),
+			}); err != nil {
+				log.Error(ctx, action.CloseRide, "error saving driver release to outbox", "error", err)
+				return err
+			}
 		}
 
 		return nil
@@ -383,5 +584,66 @@ func (svc *RideService) CloseRide(ctx context.Context, req models.CloseRideReque
 		return models.CloseRideResponse{}, err
 	}
 
-	return models.CloseRideResponse{}, nil
+	return models.CloseRideResponse{
+		RideID:      ride.ID,
+		Status:      types.RideStatusCANCELLED,
+		CancelledAt: now,
+		Message:     "Ride cancelled successfully",
+	}, nil
+}
+
+func (svc *RideService) GetRideEvents(ctx context.Context, rideID string) ([]models.RideEvent, error) {
+	log := svc.log.Func("RideService.GetRideEvents")
+
+	ride, err := svc.repo.ride.GetRide(ctx, rideID)
+	if err != nil {
+		log.Error(ctx, action.RideEvents, "error retrieving ride", "error", err)
+		return nil, err
+	}
+
+	userID := logger.GetUserID(ctx)
+	if logger.GetRole(ctx) != types.RoleAdmin && userID != ride.PassengerID && userID != ride.DriverID {
+		log.Warn(ctx, action.RideEvents, "ride events requested by a foreign user", "ride_id", ride.ID)
+		return nil, types.ErrRideAccessDenied
+	}
+
+	events, err := svc.repo.event.ListByRide(ctx, ride.ID)
+	if err != nil {
+		log.Error(ctx, action.RideEvents, "error retrieving ride events", "error", err)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// releaseUnmatchedDriver — matching уже перевёл водителя в EN_ROUTE, но поездка за это время
+// отменилась или ушла другому; повтор того же ответа водителя ничего не отпускает
+func (svc *RideService) releaseUnmatchedDriver(ctx context.Context, ride models.Ride, driverResp models.DriverResponseEvent) error {
+	if ride.DriverID == driverResp.DriverID {
+		return nil
+	}
+
+	return svc.publishDriverRelease(ctx, models.DriverReleaseEvent{
+		RideID:        ride.ID,
+		DriverID:      driverResp.DriverID,
+		Reason:        types.ReleaseReasonRideUnavailable,
+		Timestamp:     time.Now(),
+		CorrelationID: driverResp.CorrelationID,
+	})
+}
+
+// skipIfNotFound — событие о несуществующей поездке повторять бессмысленно
+func skipIfNotFound(err error) error {
+	if errors.Is(err, types.ErrRideNotFound) {
+		return nil
+	}
+	return err
+}
+
+// skipIfConflict — статус уже сменил кто-то другой, повтор ничего не изменит
+func skipIfConflict(err error) error {
+	if errors.Is(err, types.ErrRideStatusConflict) {
+		return nil
+	}
+	return err
 }
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/domain/types"
+	"ride-hail/internal/core/service/calculator"
+)
+
+// routePoints — точки маршрута по порядку: подача, остановки, назначение
+func routePoints(pickup models.Location, stops []models.Location, destination models.Location) []models.Position {
+	points := make([]models.Position, 0, len(stops)+2)
+	points = append(points, models.Position{Latitude: pickup.Lat, Longitude: pickup.Lng})
+	for _, s := range stops {
+		points = append(points, models.Position{Latitude: s.Lat, Longitude: s.Lng})
+	}
+	return append(points, models.Position{Latitude: destination.Lat, Longitude: destination.Lng})
+}
+
+// tripVia считает расстояние и время по всем участкам маршрута
+func (svc *RideService) tripVia(ctx context.Context, pickup models.Location, stops []models.Location, destination models.Location) models.Trip {
+	return calculator.TripOf(svc.routing.Legs(ctx, routePoints(pickup, stops, destination)...))
+}
+
+// saveStops сохраняет координаты остановок и привязывает их к поездке в текущей транзакции
+func (svc *RideService) saveStops(ctx context.Context, rideID, passengerID string, stops []models.Location) error {
+	for i, s := range stops {
+		coordinateID, err := svc.repo.cord.CreateNewCoordinate(ctx, models.Coordinate{
+			EntityID:   passengerID,
+			EntityType: types.EntityRolePassenger,
+			Address:    s.Address,
+			Latitude:   s.Lat,
+			Longitude:  s.Lng,
+			IsCurrent:  true,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err = svc.repo.stop.Insert(ctx, rideID, i+1, coordinateID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stopLocations возвращает остановки поездки в порядке объезда
+func (svc *RideService) stopLocations(ctx context.Context, rideID string) ([]models.Location, error) {
+	stops, err := svc.repo.stop.ListByRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]models.Location, 0, len(stops))
+	for _, s := range stops {
+		locations = append(locations, s.Location)
+	}
+	return locations, nil
+}
@@ -0,0 +1,15 @@
+package routing
+
+import (
+	"context"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/service/calculator"
+)
+
+// Haversine — маршрут по прямой между точками со средней городской скоростью; не ходит в сеть и не ошибается
+type Haversine struct{}
+
+func (Haversine) Route(_ context.Context, from, to models.Position) (models.Route, error) {
+	dist := calculator.Distance(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	return models.Route{DistanceKm: dist, DurationMinutes: calculator.Duration(dist)}, nil
+}
@@ -0,0 +1,102 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"ride-hail/config"
+	"ride-hail/internal/core/domain/action"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/internal/core/ports"
+	"ride-hail/pkg/logger"
+	"sync"
+	"time"
+)
+
+// cacheLimit — после стольких маршрутов кеш чистится от устаревших записей
+const cacheLimit = 10000
+
+type cachedRoute struct {
+	route     models.Route
+	expiresAt time.Time
+}
+
+// Router отдаёт маршрут от основного провайдера, кеширует ответы и при его ошибках
+// откатывается на расчёт по прямой, поэтому сам никогда не возвращает ошибку
+type Router struct {
+	log      *logger.Logger
+	provider ports.RoutingProvider
+	fallback Haversine
+	ttl      time.Duration
+
+	mu     sync.Mutex
+	routes map[string]cachedRoute
+}
+
+// New — provider может быть nil, тогда все маршруты считаются по прямой
+func New(log *logger.Logger, provider ports.RoutingProvider, cfg config.Routing) *Router {
+	return &Router{
+		log:      log,
+		provider: provider,
+		ttl:      time.Duration(cfg.CacheTTLSeconds) * time.Second,
+		routes:   make(map[string]cachedRoute),
+	}
+}
+
+func (r *Router) Route(ctx context.Context, from, to models.Position) models.Route {
+	if r.provider == nil {
+		route, _ := r.fallback.Route(ctx, from, to)
+		return route
+	}
+
+	key := cacheKey(from, to)
+	now := time.Now()
+
+	r.mu.Lock()
+	if c, ok := r.routes[key]; ok && now.Before(c.expiresAt) {
+		r.mu.Unlock()
+		return c.route
+	}
+	r.mu.Unlock()
+
+	route, err := r.provider.Route(ctx, from, to)
+	if err != nil {
+		// ответ по прямой не кешируется, следующий запрос снова пойдёт к провайдеру
+		r.log.Func("Router.Route").Warn(ctx, action.Routing, "routing provider failed, using straight-line route", "error", err)
+		route, _ = r.fallback.Route(ctx, from, to)
+		return route
+	}
+
+	r.mu.Lock()
+	if len(r.routes) >= cacheLimit {
+		for k, c := range r.routes {
+			if now.After(c.expiresAt) {
+				delete(r.routes, k)
+			}
+		}
+		if len(r.routes) >= cacheLimit {
+			clear(r.routes)
+		}
+	}
+	r.routes[key] = cachedRoute{route: route, expiresAt: now.Add(r.ttl)}
+	r.mu.Unlock()
+
+	return route
+}
+
+// Legs строит маршрут по участкам между соседними точками: подача, остановки, назначение
+func (r *Router) Legs(ctx context.Context, points ...models.Position) []models.Route {
+	if len(points) < 2 {
+		return nil
+	}
+
+	legs := make([]models.Route, len(points)-1)
+	for i := range legs {
+		legs[i] = r.Route(ctx, points[i], points[i+1])
+	}
+	return legs
+}
+
+// cacheKey округляет координаты до ~10 м, чтобы соседние запросы попадали в кеш
+func cacheKey(from, to models.Position) string {
+	return fmt.Sprintf("%.4f,%.4f;%.4f,%.4f", from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+}
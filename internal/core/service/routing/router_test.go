@@ -0,0 +1,76 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"ride-hail/config"
+	"ride-hail/internal/core/domain/models"
+	"ride-hail/pkg/logger"
+)
+
+type stubProvider struct {
+	calls int
+	route models.Route
+	err   error
+}
+
+func (p *stubProvider) Route(context.Context, models.Position, models.Position) (models.Route, error) {
+	p.calls++
+	return p.route, p.err
+}
+
+var (
+	testFrom = models.Position{Latitude: 43.238949, Longitude: 76.889709}
+	testTo   = models.Position{Latitude: 43.222015, Longitude: 76.851248}
+)
+
+func newTestRouter(provider *stubProvider) *Router {
+	log := logger.NewLogger("test", logger.Options{Output: io.Discard})
+	return New(log, provider, config.Routing{CacheTTLSeconds: 60})
+}
+
+func TestRouterCachesProviderRoute(t *testing.T) {
+	provider := &stubProvider{route: models.Route{DistanceKm: 7.5, DurationMinutes: 14}}
+	r := newTestRouter(provider)
+
+	for i := 0; i < 3; i++ {
+		if got := r.Route(context.Background(), testFrom, testTo); got != provider.route {
+			t.Fatalf("Route() = %+v, want %+v", got, provider.route)
+		}
+	}
+	if provider.calls != 1 {
+		t.Errorf("provider called %d times, want 1", provider.calls)
+	}
+}
+
+func TestRouterFallbackIsNotCached(t *testing.T) {
+	provider := &stubProvider{err: errors.New("osrm unavailable")}
+	r := newTestRouter(provider)
+
+	want, _ := Haversine{}.Route(context.Background(), testFrom, testTo)
+	if got := r.Route(context.Background(), testFrom, testTo); got != want {
+		t.Fatalf("Route() = %+v, want straight-line %+v", got, want)
+	}
+
+	// провайдер поднялся — следующий запрос должен уйти к нему, а не взять прямую из кеша
+	provider.err = nil
+	provider.route = models.Route{DistanceKm: 7.5, DurationMinutes: 14}
+	if got := r.Route(context.Background(), testFrom, testTo); got != provider.route {
+		t.Errorf("Route() after recovery = %+v, want %+v", got, provider.route)
+	}
+	if provider.calls != 2 {
+		t.Errorf("provider called %d times, want 2", provider.calls)
+	}
+}
+
+func TestRouterWithoutProvider(t *testing.T) {
+	r := New(logger.NewLogger("test", logger.Options{Output: io.Discard}), nil, config.Routing{})
+
+	want, _ := Haversine{}.Route(context.Background(), testFrom, testTo)
+	if got := r.Route(context.Background(), testFrom, testTo); got != want {
+		t.Errorf("Route() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,35 @@
+package surge
+
+import "testing"
+
+func TestGeohash(t *testing.T) {
+	tests := []struct {
+		lat, lng  float64
+		precision int
+		want      string
+	}{
+		{lat: 42.6, lng: -5.6, precision: 5, want: "ezs42"},
+		{lat: 57.64911, lng: 10.40744, precision: 11, want: "u4pruydqqvj"},
+		{lat: -33.8688, lng: 151.2093, precision: 6, want: "r3gx2f"},
+		{lat: 0, lng: 0, precision: 1, want: "s"},
+		{lat: 43.238949, lng: 76.889709, precision: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Geohash(tt.lat, tt.lng, tt.precision); got != tt.want {
+				t.Errorf("Geohash(%v, %v, %d) = %q, want %q", tt.lat, tt.lng, tt.precision, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeohashPrefix(t *testing.T) {
+	// ячейка меньшей точности содержит ячейку большей, поэтому её хеш — префикс
+	long := Geohash(43.238949, 76.889709, 9)
+	for p := 1; p < len(long); p++ {
+		if got := Geohash(43.238949, 76.889709, p); got != long[:p] {
+			t.Errorf("Geohash precision %d = %q, want prefix %q", p, got, long[:p])
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package surge
+
+import (
+	"context"
+	"math"
+	"ride-hail/config"
+	"ride-hail/internal/core/ports"
+	"sync"
+	"time"
+)
+
+// cacheTTL — как долго множитель зоны переиспользуется без запроса в базу
+const cacheTTL = 15 * time.Second
+
+type zoneMultiplier struct {
+	value     float64
+	expiresAt time.Time
+}
+
+// Pricer считает повышающий коэффициент по зонам-геохешам: отношение открытых заказов
+// к свободным водителям за последние Window, но не больше MaxMultiplier
+type Pricer struct {
+	repo ports.SurgeRepository
+	cfg  config.Surge
+
+	mu    sync.Mutex
+	zones map[string]zoneMultiplier
+}
+
+func New(repo ports.SurgeRepository, cfg config.Surge) *Pricer {
+	return &Pricer{
+		repo:  repo,
+		cfg:   cfg,
+		zones: make(map[string]zoneMultiplier),
+	}
+}
+
+// Multiplier возвращает коэффициент для зоны, в которую попадает точка подачи
+func (p *Pricer) Multiplier(ctx context.Context, lat, lng float64) (float64, error) {
+	zone := Geohash(lat, lng, p.cfg.ZonePrecision)
+	now := time.Now()
+
+	p.mu.Lock()
+	if m, ok := p.zones[zone]; ok && now.Before(m.expiresAt) {
+		p.mu.Unlock()
+		return m.value, nil
+	}
+	p.mu.Unlock()
+
+	window := time.Duration(p.cfg.WindowMinutes) * time.Minute
+	demand, supply, err := p.repo.ZoneSupplyDemand(ctx, zone, now.Add(-window))
+	if err != nil {
+		return 1, err
+	}
+
+	value := p.multiplier(demand, supply)
+
+	p.mu.Lock()
+	p.zones[zone] = zoneMultiplier{value: value, expiresAt: now.Add(cacheTTL)}
+	// зон немного, но устаревшие всё равно чистим, чтобы карта не росла бесконечно
+	for z, m := range p.zones {
+		if now.After(m.expiresAt) {
+			delete(p.zones, z)
+		}
+	}
+	p.mu.Unlock()
+
+	return value, nil
+}
+
+// RequiresAck сообщает, что пассажир должен явно согласиться с таким коэффициентом
+func (p *Pricer) RequiresAck(multiplier float64) bool {
+	return multiplier > p.cfg.AckThreshold
+}
+
+// multiplier — спрос на одного свободного водителя, с шагом 0.1, от 1 до MaxMultiplier
+func (p *Pricer) multiplier(demand, supply int) float64 {
+	if demand <= supply {
+		return 1
+	}
+
+	ratio := float64(demand) / float64(max(supply, 1))
+	ratio = math.Floor(ratio*10) / 10
+	return min(max(ratio, 1), p.cfg.MaxMultiplier)
+}
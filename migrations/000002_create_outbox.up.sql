@@ -0,0 +1,22 @@
+begin;
+
+-- Transactional outbox: messages are written together with business data
+-- and published to RabbitMQ by the relay after commit
+create table outbox (
+                        id uuid primary key default gen_random_uuid(),
+                        created_at timestamptz not null default now(),
+                        exchange text not null,
+                        routing_key text not null,
+                        payload jsonb not null,
+                        correlation_id text,
+                        attempts integer not null default 0,
+                        last_error text,
+                        -- failed messages are retried with backoff and parked after too many attempts
+                        next_attempt_at timestamptz not null default now(),
+                        parked_at timestamptz,
+                        sent_at timestamptz
+);
+
+create index idx_outbox_pending on outbox(created_at) where sent_at is null and parked_at is null;
+
+commit;
@@ -0,0 +1,11 @@
+begin;
+
+delete from ride_events where event_type = 'RIDE_REDISPATCHED';
+delete from "ride_event_type" where "value" = 'RIDE_REDISPATCHED';
+
+drop index if exists idx_rides_dispatch;
+alter table rides
+    drop column if exists dispatched_at,
+    drop column if exists dispatch_attempts;
+
+commit;
@@ -0,0 +1,17 @@
+begin;
+
+-- Dispatch tracking: when the ride request was last published to matching
+-- and how many times it has been re-published with a wider search radius
+alter table rides
+    add column dispatch_attempts integer not null default 0,
+    add column dispatched_at timestamptz not null default now();
+
+create index idx_rides_dispatch on rides(dispatched_at) where status = 'REQUESTED';
+
+insert into
+    "ride_event_type" ("value")
+values
+    ('RIDE_REDISPATCHED')  -- Ride request re-published with a wider radius
+;
+
+commit;
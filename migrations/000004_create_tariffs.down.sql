@@ -0,0 +1,8 @@
+begin;
+
+alter table rides drop column if exists tariff_id;
+
+drop index if exists idx_tariffs_vehicle_effective;
+drop table if exists tariffs;
+
+commit;
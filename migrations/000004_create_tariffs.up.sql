@@ -0,0 +1,32 @@
+begin;
+
+-- Versioned tariffs: a new row per change, the one with the latest
+-- effective_from that is already in effect applies to new rides
+create table tariffs (
+                         id uuid primary key default gen_random_uuid(),
+                         created_at timestamptz not null default now(),
+                         vehicle_type text references "vehicle_type"(value) not null,
+                         base_fare decimal(10,2) not null check (base_fare >= 0),
+                         rate_per_km decimal(10,2) not null check (rate_per_km >= 0),
+                         rate_per_min decimal(10,2) not null check (rate_per_min >= 0),
+                         minimum_fare decimal(10,2) not null default 0 check (minimum_fare >= 0),
+                         booking_fee decimal(10,2) not null default 0 check (booking_fee >= 0),
+                         currency varchar(3) not null default 'KZT',
+                         effective_from timestamptz not null default now()
+);
+
+create index idx_tariffs_vehicle_effective on tariffs(vehicle_type, effective_from desc);
+
+-- Rates that were hard-coded in the calculator before
+insert into
+    tariffs (vehicle_type, base_fare, rate_per_km, rate_per_min, minimum_fare, effective_from)
+values
+    ('ECONOMY', 500, 100, 50, 500, '1970-01-01 00:00:00+00'),
+    ('PREMIUM', 800, 120, 60, 800, '1970-01-01 00:00:00+00'),
+    ('XL', 1000, 150, 75, 1000, '1970-01-01 00:00:00+00')
+;
+
+-- Tariff version the fare of the ride was calculated with
+alter table rides add column tariff_id uuid references tariffs(id);
+
+commit;
@@ -0,0 +1,6 @@
+begin;
+
+-- Surge multiplier the fare of the ride was calculated with
+alter table rides add column surge_multiplier decimal(4,2) not null default 1.0 check (surge_multiplier >= 1.0);
+
+commit;
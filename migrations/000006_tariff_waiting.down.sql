@@ -0,0 +1,7 @@
+begin;
+
+alter table tariffs
+    drop column if exists waiting_rate_per_min,
+    drop column if exists free_waiting_minutes;
+
+commit;
@@ -0,0 +1,10 @@
+begin;
+
+-- Waiting at pickup: minutes after arrived_at that are free and the rate after that
+alter table tariffs
+    add column free_waiting_minutes integer not null default 3 check (free_waiting_minutes >= 0),
+    add column waiting_rate_per_min decimal(10,2) not null default 0 check (waiting_rate_per_min >= 0);
+
+update tariffs set waiting_rate_per_min = rate_per_min;
+
+commit;
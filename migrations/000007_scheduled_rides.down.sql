@@ -0,0 +1,17 @@
+begin;
+
+update rides
+set status = 'CANCELLED',
+    cancelled_at = now(),
+    cancellation_reason = 'SCHEDULING_REMOVED'
+where status = 'SCHEDULED';
+
+delete from ride_events where event_type in ('RIDE_SCHEDULED', 'RIDE_RESCHEDULED');
+delete from "ride_event_type" where "value" in ('RIDE_SCHEDULED', 'RIDE_RESCHEDULED');
+
+drop index if exists idx_rides_scheduled;
+alter table rides drop column if exists scheduled_at;
+
+delete from "ride_status" where "value" = 'SCHEDULED';
+
+commit;
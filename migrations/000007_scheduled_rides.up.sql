@@ -0,0 +1,22 @@
+begin;
+
+-- Rides booked for a future pickup stay SCHEDULED until the dispatcher
+-- publishes them to matching a lead time before scheduled_at
+insert into
+    "ride_status" ("value")
+values
+    ('SCHEDULED')  -- Ride is booked for a future pickup time
+;
+
+alter table rides add column scheduled_at timestamptz;
+
+create index idx_rides_scheduled on rides(scheduled_at) where status = 'SCHEDULED';
+
+insert into
+    "ride_event_type" ("value")
+values
+    ('RIDE_SCHEDULED'),   -- Ride booked for a future pickup time
+    ('RIDE_RESCHEDULED')  -- Passenger changed pickup time or addresses of a scheduled ride
+;
+
+commit;
@@ -0,0 +1,8 @@
+begin;
+
+delete from ride_events where event_type = 'STOP_REACHED';
+delete from "ride_event_type" where "value" = 'STOP_REACHED';
+
+drop table if exists ride_stops;
+
+commit;
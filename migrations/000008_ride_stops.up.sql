@@ -0,0 +1,20 @@
+begin;
+
+-- Intermediate stops between pickup and destination, in visiting order
+create table ride_stops (
+                            id uuid primary key default gen_random_uuid(),
+                            created_at timestamptz not null default now(),
+                            ride_id uuid not null references rides(id) on delete cascade,
+                            seq integer not null check (seq > 0),
+                            coordinate_id uuid not null references coordinates(id),
+                            reached_at timestamptz,
+                            unique (ride_id, seq)
+);
+
+insert into
+    "ride_event_type" ("value")
+values
+    ('STOP_REACHED')  -- Driver reached an intermediate stop
+;
+
+commit;
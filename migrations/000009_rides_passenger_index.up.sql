@@ -0,0 +1,6 @@
+begin;
+
+-- Passenger ride history is read newest first with a (requested_at, id) cursor
+create index idx_rides_passenger on rides(passenger_id, requested_at desc, id desc);
+
+commit;
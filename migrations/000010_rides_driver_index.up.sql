@@ -0,0 +1,6 @@
+begin;
+
+-- Driver ride history and earnings are read by driver newest first
+create index idx_rides_driver on rides(driver_id, requested_at desc, id desc) where driver_id is not null;
+
+commit;
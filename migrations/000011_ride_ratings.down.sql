@@ -0,0 +1,10 @@
+begin;
+
+delete from ride_events where event_type = 'RIDE_RATED';
+delete from "ride_event_type" where "value" = 'RIDE_RATED';
+
+drop table if exists ride_ratings;
+
+update users set attrs = attrs - 'rating' - 'rating_count' where attrs ? 'rating';
+
+commit;
@@ -0,0 +1,25 @@
+begin;
+
+-- Ratings left by the passenger for the driver and by the driver for the passenger after a completed ride
+create table ride_ratings (
+                              id uuid primary key default gen_random_uuid(),
+                              created_at timestamptz not null default now(),
+                              ride_id uuid not null references rides(id) on delete cascade,
+                              rater_id uuid not null references users(id),
+                              ratee_id uuid not null references users(id),
+                              rater_role text not null check (rater_role in ('passenger', 'driver')),
+                              score smallint not null check (score between 1 and 5),
+                              comment text,
+                              tags text[] not null default '{}',
+                              unique (ride_id, rater_id)
+);
+
+create index idx_ride_ratings_ratee on ride_ratings(ratee_id, created_at desc);
+
+insert into
+    "ride_event_type" ("value")
+values
+    ('RIDE_RATED')  -- Passenger or driver rated the ride
+;
+
+commit;
@@ -0,0 +1,6 @@
+begin;
+
+-- Surge zones look up points by geohash cell bounding box; the expression must match the queries exactly
+create index idx_coordinates_point on coordinates using gist (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326));
+
+commit;
@@ -0,0 +1,5 @@
+begin;
+
+alter table outbox drop column if exists rejections;
+
+commit;
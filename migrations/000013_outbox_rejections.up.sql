@@ -0,0 +1,6 @@
+begin;
+
+-- Only broker rejections (nack) count towards parking; connection errors are retried indefinitely
+alter table outbox add column rejections integer not null default 0;
+
+commit;
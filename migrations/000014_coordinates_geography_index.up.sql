@@ -0,0 +1,8 @@
+begin;
+
+-- Driver matching filters by ST_DWithin on geography; the expression must match FindAvailableNearby exactly
+create index idx_coordinates_current_geography on coordinates
+    using gist ((ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography))
+    where is_current = true;
+
+commit;
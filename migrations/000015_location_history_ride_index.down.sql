@@ -0,0 +1,5 @@
+begin;
+
+drop index if exists idx_location_history_ride;
+
+commit;
@@ -0,0 +1,6 @@
+begin;
+
+-- Final fare reads the driven route of a ride between started_at and completed_at
+create index if not exists idx_location_history_ride on location_history(ride_id, recorded_at);
+
+commit;
@@ -10,12 +10,33 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+const (
+	defaultPrefetch       = 10
+	defaultWorkers        = 4
+	defaultHandlerTimeout = 30 * time.Second
+)
+
+// ConsumerOptions — нулевые значения берутся из Config, а если и там пусто — из значений по умолчанию
+type ConsumerOptions struct {
+	Prefetch int // сколько неподтверждённых сообщений брокер отдаёт каналу
+	Workers  int // сколько сообщений обрабатывается одновременно, не больше Prefetch
+	// HandlerTimeout — сколько ждать обработчик; по истечении сообщение уходит на повтор,
+	// поэтому он должен покрывать самую долгую штатную обработку
+	HandlerTimeout time.Duration
+}
+
 type Consumer struct {
-	conn     *amqp.Connection
+	rabbit   *Rabbit
 	exchange string
 	queue    string
+	opts     ConsumerOptions
 	handler  MessageHandler
 	mutex    sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	ch       *amqp.Channel
+	tag      string
+	workers  sync.WaitGroup
 }
 
 type MessageHandler interface {
@@ -28,11 +49,29 @@ func (f MessageHandlerFunc) HandleMessage(ctx context.Context, message []byte, r
 	return f(ctx, message, routingKey)
 }
 
-func NewConsumer(conn *amqp.Connection, exchange, queue string) *Consumer {
+func NewConsumer(r *Rabbit, exchange, queue string, opts ConsumerOptions) *Consumer {
+	if opts.Prefetch <= 0 {
+		opts.Prefetch = r.Cfg.Prefetch
+	}
+	if opts.Prefetch <= 0 {
+		opts.Prefetch = defaultPrefetch
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = r.Cfg.Workers
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = defaultWorkers
+	}
+	opts.Workers = min(opts.Workers, opts.Prefetch)
+	if opts.HandlerTimeout <= 0 {
+		opts.HandlerTimeout = defaultHandlerTimeout
+	}
+
 	return &Consumer{
-		conn:     conn,
+		rabbit:   r,
 		exchange: exchange,
 		queue:    queue,
+		opts:     opts,
 	}
 }
 
@@ -45,31 +84,82 @@ func (c *Consumer) StartConsuming(ctx context.Context) error {
 		return errors.New("message handler not set")
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+
+	c.mutex.Lock()
+	c.ctx = ctx
+	c.cancel = cancel
+	c.mutex.Unlock()
+
+	// после переподключения Rabbit сам вызовет restart
+	c.rabbit.register(c)
+
+	if err := c.consume(ctx); err != nil {
+		cancel()
+		return err
+	}
+	return nil
+}
+
+// Stop отменяет подписку, дожидается обработки уже полученных сообщений и только потом отменяет контекст.
+// Сообщения, которые брокер не успел выдать, остаются в очереди.
+func (c *Consumer) Stop() {
+	c.mutex.Lock()
+	cancel, ch, tag := c.cancel, c.ch, c.tag
+	// канал больше не текущий — его закрытие не запустит recover
+	c.ch = nil
+	c.mutex.Unlock()
+
+	if ch != nil {
+		_ = ch.Cancel(tag, false)
+	}
+	c.workers.Wait()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// restart заново подписывается на очередь после переподключения, если консьюмер ещё не остановлен
+func (c *Consumer) restart() error {
+	c.mutex.Lock()
+	ctx := c.ctx
+	c.mutex.Unlock()
+
+	if ctx == nil || ctx.Err() != nil {
+		return nil
+	}
+	return c.consume(ctx)
+}
+
+func (c *Consumer) consume(ctx context.Context) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	if c.conn.IsClosed() {
-		return errors.New("connection is closed")
+	conn := c.rabbit.Connection()
+	if conn.IsClosed() {
+		return ErrConnectionClosed
 	}
 
-	ch, err := c.conn.Channel()
+	ch, err := conn.Channel()
 	if err != nil {
 		return fmt.Errorf("error creating channel: %w", err)
 	}
 
 	err = ch.Qos(
-		1,     // prefetch count
-		0,     // prefetch size
-		false, // global
+		c.opts.Prefetch, // prefetch count
+		0,               // prefetch size
+		false,           // global
 	)
 	if err != nil {
 		ch.Close()
 		return fmt.Errorf("error setting QoS: %w", err)
 	}
 
+	tag := c.queue + "-" + newMessageID()
 	msgs, err := ch.Consume(
 		c.queue,
-		"",    // consumer
+		tag,   // consumer
 		false, // auto-ack
 		false, // exclusive
 		false, // no-local
@@ -81,29 +171,107 @@ func (c *Consumer) StartConsuming(ctx context.Context) error {
 		return fmt.Errorf("error starting consumer: %w", err)
 	}
 
-	go c.consumeMessages(ctx, ch, msgs)
+	// повторы и DLQ публикуются на отдельном канале с подтверждениями: исходное сообщение
+	// подтверждается только после ack брокера на копию
+	pub, err := conn.Channel()
+	if err != nil {
+		ch.Close()
+		return fmt.Errorf("error creating publish channel: %w", err)
+	}
+	if err = pub.Confirm(false); err != nil {
+		pub.Close()
+		ch.Close()
+		return fmt.Errorf("error enabling confirm mode: %w", err)
+	}
+
+	// предыдущий канал больше не текущий, его горутина завершится без восстановления
+	if c.ch != nil {
+		_ = c.ch.Close()
+	}
+	c.ch = ch
+	c.tag = tag
+
+	c.workers.Add(1)
+	go c.consumeMessages(ctx, ch, pub, msgs)
 	return nil
 }
 
-func (c *Consumer) consumeMessages(ctx context.Context, ch *amqp.Channel, msgs <-chan amqp.Delivery) {
+// consumeMessages раздаёт сообщения фиксированному числу воркеров: пока все заняты,
+// новые сообщения остаются у брокера
+func (c *Consumer) consumeMessages(ctx context.Context, ch, pub *amqp.Channel, msgs <-chan amqp.Delivery) {
+	defer c.workers.Done()
 	defer ch.Close()
+	defer pub.Close()
+
+	// без канала подтверждений повторы не публикуются и сообщения крутились бы через Nack с requeue;
+	// закрываем канал подписки, чтобы консьюмер переподписался с новой парой каналов
+	pubClosed := pub.NotifyClose(make(chan *amqp.Error, 1))
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-done:
+		case <-pubClosed:
+			_ = ch.Close()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg, ok := <-msgs:
+					if !ok {
+						return
+					}
+					c.processMessage(ctx, pub, msg)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if c.isCurrent(ch) && ctx.Err() == nil && !c.rabbit.Connection().IsClosed() {
+		// закрылся только канал (например, ошибка протокола) — соединение живо, переподписываемся сами;
+		// при обрыве соединения это сделает Rabbit после переподключения
+		go c.recover(ctx)
+	}
+}
+
+func (c *Consumer) isCurrent(ch *amqp.Channel) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.ch == ch
+}
+
+func (c *Consumer) recover(ctx context.Context) {
+	backoff := reconnectMinBackoff
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case msg, ok := <-msgs:
-			if !ok {
-				return
-			}
+		case <-time.After(backoff):
+		}
 
-			go c.processMessage(ctx, msg)
+		if c.rabbit.Connection().IsClosed() {
+			return
+		}
+		if err := c.consume(ctx); err == nil {
+			return
 		}
+		backoff = min(backoff*2, reconnectMaxBackoff)
 	}
 }
 
-func (c *Consumer) processMessage(ctx context.Context, msg amqp.Delivery) {
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+func (c *Consumer) processMessage(ctx context.Context, pub *amqp.Channel, msg amqp.Delivery) {
+	hctx, cancel := context.WithTimeout(ctx, c.opts.HandlerTimeout)
 	defer cancel()
 
 	if c.handler == nil {
@@ -111,10 +279,16 @@ func (c *Consumer) processMessage(ctx context.Context, msg amqp.Delivery) {
 		return
 	}
 
-	err := c.handler.HandleMessage(ctx, msg.Body, msg.RoutingKey)
-	if err != nil {
-		msg.Nack(false, true)
-	} else {
+	err := c.handler.HandleMessage(hctx, msg.Body, originalRoutingKey(msg))
+	switch {
+	case err == nil:
 		msg.Ack(false)
+	case ctx.Err() != nil:
+		// консьюмер останавливается — сообщение не испорчено, возвращаем его в очередь как есть
+		msg.Nack(false, true)
+	case errors.Is(err, ErrPoisonMessage):
+		c.park(context.Background(), pub, msg, err.Error())
+	default:
+		c.retryOrPark(context.Background(), pub, msg, err)
 	}
 }
@@ -0,0 +1,260 @@
+package rabbit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Для каждой очереди Q объявляются:
+//   - Q.dlq   — привязана к DeadLetterExchange по ключу Q, туда уходят отклонённые и «ядовитые» сообщения;
+//   - Q.retry — привязана к RetryExchange по ключу Q, живёт RetryDelay и по TTL возвращает сообщение в Q.
+const (
+	DeadLetterExchange = "dlx"
+	RetryExchange      = "retry"
+
+	DeadLetterSuffix = ".dlq"
+	RetrySuffix      = ".retry"
+
+	RetryCountHeader         = "x-retry-count"
+	OriginalRoutingKeyHeader = "x-original-routing-key"
+	ErrorHeader              = "x-error"
+
+	MaxRetries = 3
+	RetryDelay = 5 * time.Second
+
+	republishTimeout = 5 * time.Second
+)
+
+// ErrPoisonMessage — сообщение невозможно обработать ни с какой попытки, оно сразу паркуется в DLQ
+var ErrPoisonMessage = errors.New("poison message")
+
+type DeadLetter struct {
+	MessageID     string
+	CorrelationID string
+	RoutingKey    string
+	Reason        string
+	RetryCount    int
+	Timestamp     time.Time
+	Body          []byte
+}
+
+func (r *Rabbit) ensureDeadLettering(ch *amqp.Channel, queue string) error {
+	if err := r.ensureExchange(ch, DeadLetterExchange, amqp.ExchangeDirect); err != nil {
+		return err
+	}
+	if err := r.ensureExchange(ch, RetryExchange, amqp.ExchangeDirect); err != nil {
+		return err
+	}
+
+	dlq := queue + DeadLetterSuffix
+	if _, err := ch.QueueDeclare(dlq, true, false, false, false, nil); err != nil {
+		return err
+	}
+	if err := ch.QueueBind(dlq, queue, DeadLetterExchange, false, nil); err != nil {
+		return err
+	}
+
+	retry := queue + RetrySuffix
+	if _, err := ch.QueueDeclare(retry, true, false, false, false, amqp.Table{
+		"x-message-ttl":             RetryDelay.Milliseconds(),
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": queue,
+	}); err != nil {
+		return err
+	}
+	return ch.QueueBind(retry, queue, RetryExchange, false, nil)
+}
+
+// retryOrPark отправляет сообщение на отложенный повтор, а после MaxRetries — в DLQ.
+// Исходное сообщение подтверждается только после того, как брокер подтвердил копию.
+func (c *Consumer) retryOrPark(ctx context.Context, ch *amqp.Channel, msg amqp.Delivery, cause error) {
+	attempt := retryCount(msg.Headers) + 1
+	if attempt > MaxRetries {
+		c.park(ctx, ch, msg, fmt.Sprintf("retries exhausted: %s", cause))
+		return
+	}
+
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[RetryCountHeader] = int32(attempt)
+	headers[OriginalRoutingKeyHeader] = originalRoutingKey(msg)
+
+	if err := republish(ctx, ch, RetryExchange, c.queue, msg, headers); err != nil {
+		_ = msg.Nack(false, true)
+		return
+	}
+	_ = msg.Ack(false)
+}
+
+// park кладёт сообщение в DLQ с причиной в заголовке x-error
+func (c *Consumer) park(ctx context.Context, ch *amqp.Channel, msg amqp.Delivery, reason string) {
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[ErrorHeader] = reason
+	headers[OriginalRoutingKeyHeader] = originalRoutingKey(msg)
+
+	if err := republish(ctx, ch, DeadLetterExchange, c.queue, msg, headers); err != nil {
+		// DLX из политики очереди всё равно доставит сообщение в DLQ, только без причины
+		_ = msg.Nack(false, false)
+		return
+	}
+	_ = msg.Ack(false)
+}
+
+// republish публикует копию сообщения на канале в режиме подтверждений и ждёт ack брокера
+func republish(ctx context.Context, ch *amqp.Channel, exchange, key string, msg amqp.Delivery, headers amqp.Table) error {
+	ctx, cancel := context.WithTimeout(ctx, republishTimeout)
+	defer cancel()
+
+	dc, err := ch.PublishWithDeferredConfirmWithContext(ctx, exchange, key, false, false, amqp.Publishing{
+		ContentType:   msg.ContentType,
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     msg.MessageId,
+		CorrelationId: msg.CorrelationId,
+		Timestamp:     msg.Timestamp,
+		Headers:       headers,
+		Body:          msg.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("error republishing message: %w", err)
+	}
+
+	if acked, err := dc.WaitContext(ctx); err != nil || !acked {
+		return fmt.Errorf("republished message was not confirmed: %w", errors.Join(err, ErrMessageNacked))
+	}
+	return nil
+}
+
+// PeekDeadLetters читает до limit сообщений из DLQ очереди, не удаляя их
+func (r *Rabbit) PeekDeadLetters(queue string, limit int) ([]DeadLetter, error) {
+	ch, err := r.Connection().Channel()
+	if err != nil {
+		return nil, fmt.Errorf("error creating channel: %w", err)
+	}
+	// неподтверждённые сообщения вернутся в DLQ при закрытии канала
+	defer ch.Close()
+
+	letters := make([]DeadLetter, 0, limit)
+	for len(letters) < limit {
+		msg, ok, err := ch.Get(queue+DeadLetterSuffix, false)
+		if err != nil {
+			return nil, fmt.Errorf("error reading dead letters: %w", err)
+		}
+		if !ok {
+			break
+		}
+		letters = append(letters, toDeadLetter(msg))
+	}
+
+	return letters, nil
+}
+
+// ReplayDeadLetters возвращает до limit сообщений из DLQ в исходную очередь со сброшенным счётчиком повторов
+func (r *Rabbit) ReplayDeadLetters(ctx context.Context, queue string, limit int) (int, error) {
+	ch, err := r.Connection().Channel()
+	if err != nil {
+		return 0, fmt.Errorf("error creating channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err = ch.Confirm(false); err != nil {
+		return 0, fmt.Errorf("error enabling confirm mode: %w", err)
+	}
+
+	replayed := 0
+	for replayed < limit {
+		msg, ok, err := ch.Get(queue+DeadLetterSuffix, false)
+		if err != nil {
+			return replayed, fmt.Errorf("error reading dead letters: %w", err)
+		}
+		if !ok {
+			break
+		}
+
+		headers := amqp.Table{}
+		for k, v := range msg.Headers {
+			if k == "x-death" || k == RetryCountHeader || k == ErrorHeader {
+				continue
+			}
+			headers[k] = v
+		}
+		headers[OriginalRoutingKeyHeader] = originalRoutingKey(msg)
+
+		dc, err := ch.PublishWithDeferredConfirmWithContext(ctx, "", queue, false, false, amqp.Publishing{
+			ContentType:   msg.ContentType,
+			DeliveryMode:  amqp.Persistent,
+			MessageId:     msg.MessageId,
+			CorrelationId: msg.CorrelationId,
+			Timestamp:     msg.Timestamp,
+			Headers:       headers,
+			Body:          msg.Body,
+		})
+		if err != nil {
+			_ = msg.Nack(false, true)
+			return replayed, fmt.Errorf("error replaying message: %w", err)
+		}
+
+		if acked, err := dc.WaitContext(ctx); err != nil || !acked {
+			_ = msg.Nack(false, true)
+			return replayed, fmt.Errorf("replayed message was not confirmed: %w", errors.Join(err, ErrMessageNacked))
+		}
+
+		if err = msg.Ack(false); err != nil {
+			return replayed, fmt.Errorf("error acking dead letter: %w", err)
+		}
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+func toDeadLetter(msg amqp.Delivery) DeadLetter {
+	d := DeadLetter{
+		MessageID:     msg.MessageId,
+		CorrelationID: msg.CorrelationId,
+		RoutingKey:    originalRoutingKey(msg),
+		RetryCount:    retryCount(msg.Headers),
+		Timestamp:     msg.Timestamp,
+		Body:          msg.Body,
+	}
+
+	if deaths, ok := msg.Headers["x-death"].([]interface{}); ok && len(deaths) > 0 {
+		if death, ok := deaths[0].(amqp.Table); ok {
+			d.Reason, _ = death["reason"].(string)
+		}
+	}
+	if reason, ok := msg.Headers[ErrorHeader].(string); ok {
+		d.Reason = reason
+	}
+
+	return d
+}
+
+// originalRoutingKey — ключ, с которым сообщение пришло впервые; после retry и DLX он меняется на имя очереди
+func originalRoutingKey(msg amqp.Delivery) string {
+	if rk, ok := msg.Headers[OriginalRoutingKeyHeader].(string); ok && rk != "" {
+		return rk
+	}
+	return msg.RoutingKey
+}
+
+func retryCount(headers amqp.Table) int {
+	switch v := headers[RetryCountHeader].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
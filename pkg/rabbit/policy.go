@@ -0,0 +1,77 @@
+package rabbit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+const (
+	defaultManagementPort = 15672
+	policyTimeout         = 5 * time.Second
+
+	// DeadLetterPolicyPrefix — префикс политик, которыми очередям задаётся DLX
+	DeadLetterPolicyPrefix = "dlx-"
+)
+
+// policy — тело PUT /api/policies/{vhost}/{name} management API
+type policy struct {
+	Pattern    string         `json:"pattern"`
+	ApplyTo    string         `json:"apply-to"`
+	Priority   int            `json:"priority"`
+	Definition map[string]any `json:"definition"`
+}
+
+// ensureDeadLetterPolicy задаёт очереди DLX через политику брокера, а не аргументами очереди:
+// аргументы нельзя поменять у уже объявленной очереди, а политику можно в любой момент
+func (r *Rabbit) ensureDeadLetterPolicy(ctx context.Context, queue string) error {
+	body, err := json.Marshal(policy{
+		Pattern:  "^" + regexp.QuoteMeta(queue) + "$",
+		ApplyTo:  "queues",
+		Priority: 0,
+		Definition: map[string]any{
+			"dead-letter-exchange":    DeadLetterExchange,
+			"dead-letter-routing-key": queue,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error marshalling policy: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, policyTimeout)
+	defer cancel()
+
+	endpoint := fmt.Sprintf("%s/api/policies/%s/%s", r.Cfg.managementURL(), url.PathEscape("/"), url.PathEscape(DeadLetterPolicyPrefix+queue))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating policy request: %w", err)
+	}
+	req.SetBasicAuth(r.Cfg.User, r.Cfg.Password)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error setting policy: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("error setting policy: %s: %s", resp.Status, msg)
+	}
+	return nil
+}
+
+func (c Config) managementURL() string {
+	port := c.ManagementPort
+	if port <= 0 {
+		port = defaultManagementPort
+	}
+	return fmt.Sprintf("http://%s:%d", c.Host, port)
+}
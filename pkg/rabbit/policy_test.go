@@ -0,0 +1,57 @@
+package rabbit
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestEnsureDeadLetterPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "created", status: http.StatusCreated},
+		{name: "updated", status: http.StatusNoContent},
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got policy
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Method != http.MethodPut || req.URL.EscapedPath() != "/api/policies/%2F/dlx-ride_requests" {
+					t.Errorf("request = %s %s", req.Method, req.URL.EscapedPath())
+				}
+				if user, pass, _ := req.BasicAuth(); user != "guest" || pass != "secret" {
+					t.Errorf("basic auth = %s:%s", user, pass)
+				}
+				if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+					t.Errorf("decode policy: %v", err)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			host, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+			r := &Rabbit{Cfg: Config{Host: host, User: "guest", Password: "secret"}}
+			r.Cfg.ManagementPort, _ = strconv.Atoi(port)
+
+			err := r.ensureDeadLetterPolicy(context.Background(), "ride_requests")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ensureDeadLetterPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got.Pattern != "^ride_requests$" || got.ApplyTo != "queues" ||
+				got.Definition["dead-letter-exchange"] != DeadLetterExchange ||
+				got.Definition["dead-letter-routing-key"] != "ride_requests" {
+				t.Errorf("policy = %+v", got)
+			}
+		})
+	}
+}
@@ -1,63 +1,199 @@
 package rabbit
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"ride-hail/pkg/logger"
+	"time"
+
 	amqp "github.com/rabbitmq/amqp091-go"
-	"sync"
 )
 
-type Producer struct {
-	conn  *amqp.Connection
-	mutex sync.Mutex
+const (
+	defaultPoolSize = 8
+	confirmTimeout  = 5 * time.Second
+
+	CorrelationIDHeader = "x-correlation-id"
+)
+
+var (
+	ErrConnectionClosed = errors.New("connection is closed")
+	ErrMessageNacked    = errors.New("message was nacked by broker")
+	ErrMessageReturned  = errors.New("message was returned as unroutable")
+)
+
+type messageIDKey struct{}
+
+// WithMessageID задаёт MessageId публикации, например id записи из outbox, чтобы получатель мог отсеять дубли
+func WithMessageID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, messageIDKey{}, id)
 }
 
-func NewPublisher(conn *amqp.Connection) *Producer {
-	return &Producer{
-		conn: conn,
+func getMessageID(ctx context.Context) string {
+	if id, ok := ctx.Value(messageIDKey{}).(string); ok && id != "" {
+		return id
 	}
+	return newMessageID()
 }
 
-func (p *Producer) Producer(exName, queue string, message []byte) error {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
+// confirmChannel — канал в режиме подтверждений со своими подписками на ack и return
+type confirmChannel struct {
+	ch      *amqp.Channel
+	returns chan amqp.Return
+}
 
-	if p.conn.IsClosed() {
-		return errors.New("connection is closed")
+type Producer struct {
+	rabbit *Rabbit
+	pool   chan *confirmChannel
+}
+
+func NewPublisher(r *Rabbit) *Producer {
+	return &Producer{
+		rabbit: r,
+		pool:   make(chan *confirmChannel, defaultPoolSize),
 	}
+}
 
-	ch, err := p.conn.Channel()
+// Producer публикует persistent-сообщение с mandatory и ждёт подтверждения брокера.
+// Корреляция берётся из request id контекста.
+func (p *Producer) Producer(ctx context.Context, exName, routingKey string, message []byte) error {
+	cc, err := p.acquire()
 	if err != nil {
-		return fmt.Errorf("error in creating channel %w", err)
+		return err
 	}
-	defer ch.Close()
 
-	_, err = ch.QueueDeclare(
-		queue,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
+	ctx, cancel := context.WithTimeout(ctx, confirmTimeout)
+	defer cancel()
 
-	if err != nil {
-		return fmt.Errorf("error in declaring queue %w", err)
-	}
+	msgID := getMessageID(ctx)
+	correlationID := logger.GetRequestID(ctx)
 
-	err = ch.Publish(
+	dc, err := cc.ch.PublishWithDeferredConfirmWithContext(
+		ctx,
 		exName,
-		queue,
-		false,
+		routingKey,
+		true,
 		false,
 		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        message,
+			ContentType:   "application/json",
+			DeliveryMode:  amqp.Persistent,
+			MessageId:     msgID,
+			CorrelationId: correlationID,
+			Timestamp:     time.Now().UTC(),
+			Headers:       amqp.Table{CorrelationIDHeader: correlationID},
+			Body:          message,
 		},
 	)
-
 	if err != nil {
+		p.discard(cc)
 		return fmt.Errorf("error in publishing message %w", err)
 	}
+
+	acked, err := dc.WaitContext(ctx)
+	if err != nil {
+		// без подтверждения состояние канала неизвестно, в пул его не возвращаем
+		p.discard(cc)
+		return fmt.Errorf("error in waiting for confirm %w", err)
+	}
+
+	// basic.return приходит до basic.ack, поэтому к этому моменту он уже в буфере
+	returned := cc.takeReturn(msgID)
+	p.release(cc)
+
+	if !acked {
+		return ErrMessageNacked
+	}
+	if returned {
+		return fmt.Errorf("%w: exchange %q, routing key %q", ErrMessageReturned, exName, routingKey)
+	}
 	return nil
 }
+
+func (p *Producer) acquire() (*confirmChannel, error) {
+	for {
+		select {
+		case cc := <-p.pool:
+			if cc.ch.IsClosed() {
+				continue
+			}
+			return cc, nil
+		default:
+			return p.open()
+		}
+	}
+}
+
+func (p *Producer) open() (*confirmChannel, error) {
+	conn := p.rabbit.Connection()
+	if conn.IsClosed() {
+		return nil, ErrConnectionClosed
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("error in creating channel %w", err)
+	}
+
+	if err = ch.Confirm(false); err != nil {
+		_ = ch.Close()
+		return nil, fmt.Errorf("error in enabling confirm mode %w", err)
+	}
+
+	return &confirmChannel{
+		ch:      ch,
+		returns: ch.NotifyReturn(make(chan amqp.Return, defaultPoolSize)),
+	}, nil
+}
+
+func (p *Producer) release(cc *confirmChannel) {
+	select {
+	case p.pool <- cc:
+	default:
+		_ = cc.ch.Close()
+	}
+}
+
+func (p *Producer) discard(cc *confirmChannel) {
+	_ = cc.ch.Close()
+}
+
+// Close закрывает каналы, оставшиеся в пуле
+func (p *Producer) Close() {
+	for {
+		select {
+		case cc := <-p.pool:
+			_ = cc.ch.Close()
+		default:
+			return
+		}
+	}
+}
+
+// takeReturn вычитывает накопившиеся return и сообщает, был ли среди них msgID
+func (cc *confirmChannel) takeReturn(msgID string) bool {
+	returned := false
+	for {
+		select {
+		case r, ok := <-cc.returns:
+			if !ok {
+				return returned
+			}
+			if r.MessageId == msgID {
+				returned = true
+			}
+		default:
+			return returned
+		}
+	}
+}
+
+func newMessageID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -1,14 +1,36 @@
 package rabbit
 
 import (
+	"context"
 	"fmt"
+	"ride-hail/pkg/logger"
+	"sync"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+const (
+	reconnectMinBackoff = time.Second
+	reconnectMaxBackoff = 30 * time.Second
+
+	actionReconnect = "rabbit reconnect"
+	actionTopology  = "rabbit topology"
+)
+
+// Rabbit держит соединение и восстанавливает его при обрыве:
+// переподключается с backoff, заново объявляет топологию и перезапускает зарегистрированных консьюмеров
 type Rabbit struct {
-	Conn *amqp.Connection
-	Cfg  Config
+	Cfg Config
+
+	mu        sync.RWMutex
+	conn      *amqp.Connection
+	topology  func(r *Rabbit) error
+	consumers []*Consumer
+
+	log  *logger.Logger
+	done chan struct{}
+	once sync.Once
 }
 
 type Config struct {
@@ -16,6 +38,10 @@ type Config struct {
 	Port     int
 	User     string
 	Password string
+	Prefetch int
+	Workers  int
+	// ManagementPort — порт management API, через него задаются политики dead-letter
+	ManagementPort int
 }
 
 func (c Config) GetRabbitDsn() string {
@@ -28,27 +54,151 @@ func (c Config) GetRabbitDsn() string {
 	)
 }
 
-func New(cfg Config) (*Rabbit, error) {
+func New(cfg Config, log *logger.Logger) (*Rabbit, error) {
 	conn, err := amqp.Dial(cfg.GetRabbitDsn())
 	if err != nil {
 		return nil, err
 	}
-	return &Rabbit{Conn: conn, Cfg: cfg}, nil
+
+	r := &Rabbit{
+		Cfg:  cfg,
+		conn: conn,
+		log:  log,
+		done: make(chan struct{}),
+	}
+	go r.supervise()
+
+	return r, nil
+}
+
+// Connection возвращает текущее соединение, после переподключения это уже другой объект
+func (r *Rabbit) Connection() *amqp.Connection {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.conn
+}
+
+// SetTopology объявляет топологию сейчас и запоминает её для повторного объявления после переподключения
+func (r *Rabbit) SetTopology(fn func(r *Rabbit) error) error {
+	if err := fn(r); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.topology = fn
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *Rabbit) register(c *Consumer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.consumers {
+		if existing == c {
+			return
+		}
+	}
+	r.consumers = append(r.consumers, c)
 }
 
 func (r *Rabbit) Close() {
-	if r.Conn != nil {
-		_ = r.Conn.Close()
+	r.once.Do(func() {
+		close(r.done)
+	})
+
+	if conn := r.Connection(); conn != nil {
+		_ = conn.Close()
 	}
 }
 
+func (r *Rabbit) supervise() {
+	log := r.log.Func("Rabbit.supervise")
+	ctx := context.Background()
+
+	for {
+		closeCh := r.Connection().NotifyClose(make(chan *amqp.Error, 1))
+
+		select {
+		case <-r.done:
+			return
+		case amqpErr := <-closeCh:
+			select {
+			case <-r.done:
+				return
+			default:
+			}
+
+			log.Warn(ctx, actionReconnect, "connection lost, reconnecting", "error", amqpErr)
+			if !r.reconnect(ctx) {
+				return
+			}
+			log.Info(ctx, actionReconnect, "connection restored")
+		}
+	}
+}
+
+// reconnect переподключается до успеха или до Close; false — остановлен через Close
+func (r *Rabbit) reconnect(ctx context.Context) bool {
+	log := r.log.Func("Rabbit.reconnect")
+	backoff := reconnectMinBackoff
+
+	for {
+		select {
+		case <-r.done:
+			return false
+		case <-time.After(backoff):
+		}
+
+		if err := r.redial(); err != nil {
+			log.Warn(ctx, actionReconnect, "reconnect attempt failed", "backoff", backoff.String(), "error", err)
+			if backoff < reconnectMaxBackoff {
+				backoff = min(backoff*2, reconnectMaxBackoff)
+			}
+			continue
+		}
+
+		r.mu.RLock()
+		consumers := append([]*Consumer(nil), r.consumers...)
+		r.mu.RUnlock()
+
+		for _, c := range consumers {
+			if err := c.restart(); err != nil {
+				log.Error(ctx, actionReconnect, "failed to restart consumer", "queue", c.queue, "error", err)
+			}
+		}
+		return true
+	}
+}
+
+func (r *Rabbit) redial() error {
+	conn, err := amqp.Dial(r.Cfg.GetRabbitDsn())
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.conn = conn
+	topology := r.topology
+	r.mu.Unlock()
+
+	if topology != nil {
+		if err = topology(r); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("error declaring topology: %w", err)
+		}
+	}
+
+	return nil
+}
+
 type QueueConfig struct {
 	Name       string
 	RoutingKey string
 }
 
 func (r *Rabbit) SetupExchangesAndQueues(exchangeName, exchangeType string, queues []QueueConfig) error {
-	ch, err := r.Conn.Channel()
+	ch, err := r.Connection().Channel()
 	if err != nil {
 		return err
 	}
@@ -59,11 +209,20 @@ func (r *Rabbit) SetupExchangesAndQueues(exchangeName, exchangeType string, queu
 	}
 
 	for _, qCfg := range queues {
+		if err = r.ensureDeadLettering(ch, qCfg.Name); err != nil {
+			return err
+		}
+
 		q, err := r.ensureQueue(ch, qCfg.Name)
 		if err != nil {
 			return err
 		}
 
+		// без политики DLQ всё равно пополняется консьюмером, теряется только запасной путь через DLX
+		if err = r.ensureDeadLetterPolicy(context.Background(), qCfg.Name); err != nil {
+			r.log.Func("Rabbit.SetupExchangesAndQueues").Warn(context.Background(), actionTopology, "failed to set dead-letter policy", "queue", qCfg.Name, "error", err)
+		}
+
 		if err = ch.QueueBind(
 			q.Name,
 			qCfg.RoutingKey,
@@ -82,6 +241,8 @@ func (r *Rabbit) ensureExchange(ch *amqp.Channel, name, kind string) error {
 	return ch.ExchangeDeclare(name, kind, true, false, false, false, nil)
 }
 
+// ensureQueue объявляет рабочую очередь без аргументов, чтобы объявление совпадало с уже созданными у брокера;
+// DLX ей задаёт политика из ensureDeadLetterPolicy
 func (r *Rabbit) ensureQueue(ch *amqp.Channel, name string) (amqp.Queue, error) {
 	return ch.QueueDeclare(name, true, false, false, false, nil)
 }